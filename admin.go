@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// minAdminToken is the shortest admin.token accepted, in bytes.
+const minAdminToken = 16
+
+// AdminConfig holds the settings of the admin-only endpoints.
+type AdminConfig struct {
+	// Token must be sent as a bearer token to reach the admin endpoints,
+	// which are disabled while it is empty.
+	Token string `yaml:"token"`
+}
+
+// requireAdmin only lets requests carrying the admin token through to next.
+// Without a configured token the admin endpoints do not exist.
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.admin.Token == "" {
+			http.NotFound(w, r)
+			return
+		}
+		header := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == header || subtle.ConstantTimeCompare([]byte(token), []byte(s.admin.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// purgeInterview deletes an interview and its history for good, unlike
+// DELETE /interview/{id}, which cancels it. An interview in a loop leaves
+// it, and an emptied loop is deleted. Nobody is notified.
+func (s *Server) purgeInterview(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := s.interviews.GetInterview(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = s.interviews.DeleteInterview(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	SendResponse(map[string]string{"message": "Interview Purged Successfully"}, w)
+}
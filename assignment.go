@@ -0,0 +1,409 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoEligibleInterviewer is returned when no interviewer has every
+	// requested skill.
+	ErrNoEligibleInterviewer = errors.New("no eligible interviewer")
+	// ErrNoInterviewerFree is returned when every eligible interviewer is
+	// busy or unavailable.
+	ErrNoInterviewerFree = errors.New("no eligible interviewer is free")
+	// ErrUnknownStrategy is returned for an assignment strategy that is not
+	// registered in assignmentStrategies.
+	ErrUnknownStrategy = errors.New("unknown assignment strategy")
+	// ErrInvalidWindow is returned for an assignment window that is empty
+	// or too long to search.
+	ErrInvalidWindow = errors.New("invalid assignment window")
+)
+
+// Assignment records how an interviewer was picked for an interview.
+type Assignment struct {
+	InterviewID   int       `json:"interview_id"`
+	InterviewerID int       `json:"interviewer_id"`
+	Strategy      string    `json:"strategy"`
+	Skills        []string  `json:"skills"`
+	Reason        string    `json:"reason"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// assignRequest asks createInterview to pick the interviewer itself.
+type assignRequest struct {
+	Skills []string `json:"skills"`
+	// Strategy defaults to scheduling.assignment_strategy.
+	Strategy string `json:"strategy"`
+	// From and To, when both set, are a window in which to place the
+	// interview: it is booked at the earliest quarter hour at which an
+	// eligible interviewer and the other participants are free for its
+	// whole duration, and scheduled_time may be left out. Without them the
+	// interview keeps its scheduled_time.
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// windowed reports whether the request gives a window to search.
+func (req assignRequest) windowed() bool {
+	return !req.From.IsZero() && !req.To.IsZero()
+}
+
+// createInterviewRequest is the body of POST /interview. When Assign is set
+// and interviewer_id is left out, an interviewer is assigned automatically.
+type createInterviewRequest struct {
+	Interview
+	Assign *assignRequest `json:"assign"`
+}
+
+// rankedInterviewer is an eligible interviewer together with why a
+// strategy ranked them where it did.
+type rankedInterviewer struct {
+	Interviewer
+	reason string
+}
+
+// strategyStore is the data an AssignmentStrategy may consult.
+type strategyStore interface {
+	InterviewStore
+	AssignmentStore
+}
+
+// AssignmentStrategy orders the interviewers eligible for an interview by
+// preference. The first of them free for the interview's slot is assigned.
+type AssignmentStrategy interface {
+	Rank(ctx context.Context, st strategyStore, eligible []Interviewer, interview Interview) ([]rankedInterviewer, error)
+}
+
+// assignmentStrategies holds the strategies selectable by name, through
+// scheduling.assignment_strategy or per request.
+var assignmentStrategies = map[string]AssignmentStrategy{
+	"round_robin":  roundRobin{},
+	"least_loaded": leastLoaded{},
+	"random":       randomStrategy{},
+}
+
+func strategyNames() []string {
+	names := make([]string, 0, len(assignmentStrategies))
+	for name := range assignmentStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// roundRobin starts with the eligible interviewer following the one it
+// assigned last, by id, wrapping around.
+type roundRobin struct{}
+
+func (roundRobin) Rank(ctx context.Context, st strategyStore, eligible []Interviewer, interview Interview) ([]rankedInterviewer, error) {
+	last, err := st.LastAssignment(ctx, "round_robin")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	next := sort.Search(len(eligible), func(i int) bool { return eligible[i].ID > last.InterviewerID })
+	ranked := make([]rankedInterviewer, 0, len(eligible))
+	for i := range eligible {
+		interviewer := eligible[(next+i)%len(eligible)]
+		reason := "round robin: first in turn"
+		if last.InterviewerID != 0 {
+			reason = fmt.Sprintf("round robin: next in turn after interviewer %d", last.InterviewerID)
+		}
+		ranked = append(ranked, rankedInterviewer{interviewer, reason})
+	}
+	return ranked, nil
+}
+
+// leastLoaded prefers the interviewers with the fewest interviews in the
+// week (Monday to Sunday, UTC) of the interview.
+type leastLoaded struct{}
+
+func (leastLoaded) Rank(ctx context.Context, st strategyStore, eligible []Interviewer, interview Interview) ([]rankedInterviewer, error) {
+	day := interview.ScheduledTime.UTC()
+	weekStart := time.Date(day.Year(), day.Month(), day.Day()-(int(day.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	load := map[int]int{}
+	for _, interviewer := range eligible {
+		booked, err := st.FindConflicts(ctx, []Participant{{RoleInterviewer, interviewer.ID}}, weekStart, weekEnd)
+		if err != nil {
+			return nil, err
+		}
+		load[interviewer.ID] = len(booked)
+	}
+
+	ranked := make([]rankedInterviewer, 0, len(eligible))
+	for _, interviewer := range eligible {
+		reason := fmt.Sprintf("least loaded: %d interviews in the week of %s", load[interviewer.ID], weekStart.Format("2006-01-02"))
+		ranked = append(ranked, rankedInterviewer{interviewer, reason})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return load[ranked[i].ID] < load[ranked[j].ID] })
+	return ranked, nil
+}
+
+// randomStrategy tries the eligible interviewers in random order.
+type randomStrategy struct{}
+
+func (randomStrategy) Rank(ctx context.Context, st strategyStore, eligible []Interviewer, interview Interview) ([]rankedInterviewer, error) {
+	ranked := make([]rankedInterviewer, 0, len(eligible))
+	for _, interviewer := range eligible {
+		ranked = append(ranked, rankedInterviewer{interviewer, fmt.Sprintf("random pick among %d eligible interviewers", len(eligible))})
+	}
+	rand.Shuffle(len(ranked), func(i, j int) { ranked[i], ranked[j] = ranked[j], ranked[i] })
+	return ranked, nil
+}
+
+// normalizeSkills lower-cases and trims skills, dropping blanks and
+// duplicates, and sorts them.
+func normalizeSkills(skills []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, skill := range skills {
+		skill = strings.ToLower(strings.TrimSpace(skill))
+		if skill == "" || seen[skill] {
+			continue
+		}
+		if len(skill) > 64 || strings.Contains(skill, ",") {
+			return nil, fmt.Errorf("invalid skill %q: skills are at most 64 characters and cannot contain commas", skill)
+		}
+		seen[skill] = true
+		normalized = append(normalized, skill)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// assign books interview with an interviewer picked from those having every
+// skill in req, trying them in the order given by the strategy until one is
+// free. With a window in req, the interview is first moved to the earliest
+// slot of the window at which any of them is free. When the other participants are busy it returns their conflicts;
+// when every interviewer is, it fails with ErrNoInterviewerFree and returns
+// the conflicts of all of them.
+func (s *Server) assign(ctx context.Context, interview *Interview, req assignRequest) (Assignment, []Conflict, error) {
+	name := req.Strategy
+	if name == "" {
+		name = s.scheduling.AssignmentStrategy
+	}
+	strategy, ok := assignmentStrategies[name]
+	if !ok {
+		return Assignment{}, nil, fmt.Errorf("%w %q: must be one of %s", ErrUnknownStrategy, name, strings.Join(strategyNames(), ", "))
+	}
+
+	skills, err := normalizeSkills(req.Skills)
+	if err != nil {
+		return Assignment{}, nil, err
+	}
+	eligible, err := s.assignments.FindInterviewersWithSkills(ctx, skills)
+	if err != nil {
+		return Assignment{}, nil, err
+	}
+	if len(eligible) == 0 {
+		if len(skills) == 0 {
+			return Assignment{}, nil, fmt.Errorf("%w: there are no interviewers", ErrNoEligibleInterviewer)
+		}
+		return Assignment{}, nil, fmt.Errorf("%w: nobody has all of the skills %s", ErrNoEligibleInterviewer, strings.Join(skills, ", "))
+	}
+
+	ranked, err := strategy.Rank(ctx, struct {
+		InterviewStore
+		AssignmentStore
+	}{s.interviews, s.assignments}, eligible, *interview)
+	if err != nil {
+		return Assignment{}, nil, err
+	}
+
+	members := interview.Panel
+	for _, p := range members {
+		if p.Role == PanelLead {
+			return Assignment{}, nil, fmt.Errorf("%w: the lead is picked automatically", ErrInvalidPanel)
+		}
+	}
+
+	var placed string
+	if req.windowed() {
+		err := s.placeInWindow(ctx, interview, ranked, req.From, req.To)
+		if err != nil {
+			return Assignment{}, nil, err
+		}
+		placed = fmt.Sprintf("; earliest free slot between %s and %s", formatTime(req.From), formatTime(req.To))
+	}
+
+	var busy []Conflict
+	var skipped []string
+	for _, candidate := range ranked {
+		if (Interview{Panel: members}).onPanel(candidate.ID) {
+			continue
+		}
+		interview.InterviewerID = candidate.ID
+		interview.Panel = append([]Panelist{{candidate.ID, PanelLead}}, members...)
+		if err := interview.normalizePanel(); err != nil {
+			return Assignment{}, nil, err
+		}
+		assignment := Assignment{
+			InterviewerID: candidate.ID,
+			Strategy:      name,
+			Skills:        skills,
+			Reason:        candidate.reason + placed,
+			CreatedAt:     time.Now().UTC().Truncate(time.Second),
+		}
+		if len(skipped) > 0 {
+			assignment.Reason += "; skipped busy interviewers " + strings.Join(skipped, ", ")
+		}
+
+		conflicts, err := s.bookWith(ctx, interview, func(tx Store) error {
+			assignment.InterviewID = interview.ID
+			return tx.AddAssignment(ctx, assignment)
+		})
+		if err != nil {
+			return Assignment{}, nil, err
+		}
+		if len(conflicts) == 0 {
+			return assignment, nil, nil
+		}
+		if others := othersBusy(conflicts, candidate.ID); len(others) > 0 {
+			// No other interviewer would help.
+			interview.InterviewerID, interview.Panel = 0, members
+			return Assignment{}, others, nil
+		}
+		busy = append(busy, conflicts...)
+		skipped = append(skipped, fmt.Sprint(candidate.ID))
+	}
+
+	interview.InterviewerID, interview.Panel = 0, members
+	return Assignment{}, busy, fmt.Errorf("%w from %s to %s", ErrNoInterviewerFree, formatTime(interview.ScheduledTime), formatTime(interview.EndTime))
+}
+
+// placeInWindow moves interview to the earliest slot in [from, to) at which
+// one of ranked, as lead, and the other participants are all free. It only
+// reads availability; the slot is claimed when the interview is booked.
+func (s *Server) placeInWindow(ctx context.Context, interview *Interview, ranked []rankedInterviewer, from, to time.Time) error {
+	from, to = from.UTC().Truncate(time.Second), to.UTC().Truncate(time.Second)
+	if !from.Before(to) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidWindow)
+	}
+	if to.Sub(from) > maxSuggestionRange {
+		return fmt.Errorf("%w: from and to can be at most %s apart", ErrInvalidWindow, maxSuggestionRange)
+	}
+
+	var earliest time.Time
+	for _, candidate := range ranked {
+		if interview.onPanel(candidate.ID) {
+			continue
+		}
+		trial := *interview
+		trial.InterviewerID = candidate.ID
+		trial.Panel = append([]Panelist{{candidate.ID, PanelLead}}, interview.Panel...)
+		free, err := s.freeTime(ctx, trial.participants(), from, to)
+		if err != nil {
+			return err
+		}
+		slots := suggestSlots(free, interview.Duration(), 1)
+		if len(slots) > 0 && (earliest.IsZero() || slots[0].ScheduledTime.Before(earliest)) {
+			earliest = slots[0].ScheduledTime
+		}
+	}
+	if earliest.IsZero() {
+		return fmt.Errorf("%w between %s and %s", ErrNoInterviewerFree, formatTime(from), formatTime(to))
+	}
+	interview.ScheduledTime = earliest
+	interview.EndTime = earliest.Add(interview.Duration())
+	return nil
+}
+
+// othersBusy returns the conflicts that do not concern the interviewer
+// being assigned as lead.
+func othersBusy(conflicts []Conflict, lead int) []Conflict {
+	var others []Conflict
+	for _, c := range conflicts {
+		if c.Role != RoleInterviewer || c.ParticipantID != lead {
+			others = append(others, c)
+		}
+	}
+	return others
+}
+
+func (s *Server) getInterviewerSkills(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := s.interviewers.GetInterviewer(r.Context(), id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	skills, err := s.assignments.GetInterviewerSkills(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string][]string{"skills": skills})
+}
+
+func (s *Server) setInterviewerSkills(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Skills []string `json:"skills"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	skills, err := normalizeSkills(body.Skills)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.interviewers.GetInterviewer(r.Context(), id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = s.assignments.SetInterviewerSkills(r.Context(), id, skills)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	SendResponse(map[string]string{"message": "Interviewer Skills Updated Successfully"}, w)
+}
+
+func (s *Server) getAssignment(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	assignment, err := s.assignments.GetAssignment(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	json.NewEncoder(w).Encode(assignment)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssignmentStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		setup    func(t *testing.T, store Store)
+		want     []int
+		reasons  []string
+	}{
+		{
+			name: "round robin first", strategy: "round_robin",
+			want:    []int{1, 2},
+			reasons: []string{"round robin: first in turn", "round robin: first in turn"},
+		},
+		{
+			name: "round robin after the last", strategy: "round_robin",
+			setup: func(t *testing.T, store Store) {
+				err := store.AddAssignment(context.Background(), Assignment{InterviewID: 1, InterviewerID: 1, Strategy: "round_robin", CreatedAt: testTime})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			want:    []int{2, 1},
+			reasons: []string{"round robin: next in turn after interviewer 1", "round robin: next in turn after interviewer 1"},
+		},
+		{
+			name: "least loaded", strategy: "least_loaded",
+			setup: func(t *testing.T, store Store) {
+				if _, err := store.CreateInterview(context.Background(), testInterview(testTime.Add(48*time.Hour))); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want:    []int{2, 1},
+			reasons: []string{"least loaded: 0 interviews in the week of 2030-03-04", "least loaded: 1 interviews in the week of 2030-03-04"},
+		},
+		{
+			name: "random", strategy: "random",
+			reasons: []string{"random pick among 2 eligible interviewers", "random pick among 2 eligible interviewers"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemoryStore()
+			seedPeople(t, ctx, store)
+			if tt.setup != nil {
+				tt.setup(t, store)
+			}
+			eligible, err := store.FindInterviewersWithSkills(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ranked, err := assignmentStrategies[tt.strategy].Rank(ctx, store, eligible, testInterview(testTime))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ranked) != len(eligible) {
+				t.Fatalf("ranked %d of %d eligible interviewers", len(ranked), len(eligible))
+			}
+			seen := map[int]bool{}
+			for i, r := range ranked {
+				seen[r.ID] = true
+				if tt.want != nil && r.ID != tt.want[i] {
+					t.Errorf("rank %d: got interviewer %d, want %d", i, r.ID, tt.want[i])
+				}
+				if r.reason != tt.reasons[i] {
+					t.Errorf("rank %d: got reason %q, want %q", i, r.reason, tt.reasons[i])
+				}
+			}
+			if len(seen) != len(eligible) {
+				t.Errorf("ranked the same interviewer twice: %+v", ranked)
+			}
+		})
+	}
+}
+
+func TestAutoAssign(t *testing.T) {
+	// off is time off of interviewer id from start for hours.
+	type off struct {
+		id    int
+		start time.Time
+		hours int
+	}
+	tests := []struct {
+		name       string
+		off        []off
+		body       string
+		wantCode   int
+		wantLead   int
+		wantStart  time.Time
+		wantReason string
+	}{
+		{
+			name:       "first in turn",
+			body:       `{"candidate_id": 1, "hr_id": 1, "scheduled_time": "2030-03-04T10:00:00Z", "assign": {"strategy": "round_robin"}}`,
+			wantCode:   http.StatusOK,
+			wantLead:   1,
+			wantStart:  testTime,
+			wantReason: "round robin: first in turn",
+		},
+		{
+			name:       "skipping a busy interviewer",
+			off:        []off{{1, testTime, 1}},
+			body:       `{"candidate_id": 1, "hr_id": 1, "scheduled_time": "2030-03-04T10:00:00Z", "assign": {"strategy": "round_robin"}}`,
+			wantCode:   http.StatusOK,
+			wantLead:   2,
+			wantStart:  testTime,
+			wantReason: "round robin: first in turn; skipped busy interviewers 1",
+		},
+		{
+			name:     "nobody free",
+			off:      []off{{1, testTime, 1}, {2, testTime, 1}},
+			body:     `{"candidate_id": 1, "hr_id": 1, "scheduled_time": "2030-03-04T10:00:00Z", "assign": {"strategy": "round_robin"}}`,
+			wantCode: http.StatusConflict,
+		},
+		{
+			// Interviewer 2 is free from 11:00, interviewer 1 from 12:00.
+			name:       "window",
+			off:        []off{{1, testTime, 2}, {2, testTime, 1}},
+			body:       `{"candidate_id": 1, "hr_id": 1, "duration_minutes": 60, "assign": {"strategy": "round_robin", "from": "2030-03-04T10:00:00Z", "to": "2030-03-04T14:00:00Z"}}`,
+			wantCode:   http.StatusOK,
+			wantLead:   2,
+			wantStart:  testTime.Add(time.Hour),
+			wantReason: "round robin: first in turn; earliest free slot between Mon, 04 Mar 2030 10:00 UTC and Mon, 04 Mar 2030 14:00 UTC; skipped busy interviewers 1",
+		},
+		{
+			name:     "nobody free in the window",
+			off:      []off{{1, testTime, 4}, {2, testTime, 4}},
+			body:     `{"candidate_id": 1, "hr_id": 1, "duration_minutes": 60, "assign": {"from": "2030-03-04T10:00:00Z", "to": "2030-03-04T14:00:00Z"}}`,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "empty window",
+			body:     `{"candidate_id": 1, "hr_id": 1, "assign": {"from": "2030-03-04T10:00:00Z", "to": "2030-03-04T10:00:00Z"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemoryStore()
+			seedPeople(t, ctx, store)
+			for _, o := range tt.off {
+				_, err := store.CreateTimeOff(ctx, TimeOff{OwnerRole: RoleInterviewer, OwnerID: o.id, Kind: TimeOffBlock, StartTime: o.start, EndTime: o.start.Add(time.Duration(o.hours) * time.Hour)})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			s, _ := newTestServer(store)
+
+			w := httptest.NewRecorder()
+			s.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/interview", strings.NewReader(tt.body)))
+			if w.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				interviews, err := store.ListInterviews(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(interviews) > 0 {
+					t.Errorf("stored %+v", interviews)
+				}
+				return
+			}
+
+			var resp struct {
+				Assignment    Assignment `json:"assignment"`
+				ScheduledTime time.Time  `json:"scheduled_time"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			interview, err := store.GetInterview(ctx, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if interview.InterviewerID != tt.wantLead || !interview.ScheduledTime.Equal(tt.wantStart) || !resp.ScheduledTime.Equal(tt.wantStart) {
+				t.Errorf("got interviewer %d at %s (%s in the response), want %d at %s", interview.InterviewerID, interview.ScheduledTime, resp.ScheduledTime, tt.wantLead, tt.wantStart)
+			}
+			assignment, err := store.GetAssignment(ctx, interview.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if assignment.InterviewerID != tt.wantLead || assignment.Reason != tt.wantReason {
+				t.Errorf("recorded %+v, want interviewer %d because %q", assignment, tt.wantLead, tt.wantReason)
+			}
+			if resp.Assignment.Reason != assignment.Reason {
+				t.Errorf("responded with %q, recorded %q", resp.Assignment.Reason, assignment.Reason)
+			}
+		})
+	}
+}
+
+func TestRoundRobinTakesTurns(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	seedPeople(t, ctx, store)
+	s, _ := newTestServer(store)
+	router := s.routes()
+
+	for i, want := range []int{1, 2, 1} {
+		body := fmt.Sprintf(`{"candidate_id": 1, "hr_id": 1, "scheduled_time": %q, "assign": {"strategy": "round_robin"}}`,
+			testTime.Add(time.Duration(i)*24*time.Hour).Format(time.RFC3339))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/interview", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", w.Code, w.Body)
+		}
+		assignment, err := store.GetAssignment(ctx, i+1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if assignment.InterviewerID != want {
+			t.Errorf("interview %d: assigned interviewer %d, want %d", i+1, assignment.InterviewerID, want)
+		}
+	}
+}
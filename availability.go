@@ -0,0 +1,459 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ClockTime is a time of day in minutes after midnight. It is written as
+// "15:04" in JSON; "24:00" denotes the end of the day.
+type ClockTime int
+
+func (c ClockTime) String() string {
+	return fmt.Sprintf("%02d:%02d", int(c)/60, int(c)%60)
+}
+
+func (c ClockTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+func (c *ClockTime) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	hours, minutes, ok := strings.Cut(text, ":")
+	h, herr := strconv.Atoi(hours)
+	m, merr := strconv.Atoi(minutes)
+	if !ok || herr != nil || merr != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return fmt.Errorf("invalid time of day %q, expected HH:MM", text)
+	}
+	*c = ClockTime(h*60 + m)
+	return nil
+}
+
+// WorkingHours is a weekly recurring window during which a person can be
+// booked, in the owner's time zone. People without any working hours can be
+// booked at any time.
+type WorkingHours struct {
+	ID        int          `json:"id"`
+	OwnerRole string       `json:"owner_role"`
+	OwnerID   int          `json:"owner_id"`
+	Weekday   time.Weekday `json:"weekday"`
+	StartTime ClockTime    `json:"start_time"`
+	EndTime   ClockTime    `json:"end_time"`
+}
+
+func (wh WorkingHours) owner() Participant {
+	return Participant{wh.OwnerRole, wh.OwnerID}
+}
+
+func (wh WorkingHours) validate() error {
+	if wh.Weekday < time.Sunday || wh.Weekday > time.Saturday {
+		return errors.New("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	if wh.StartTime >= wh.EndTime {
+		return errors.New("start_time must be before end_time")
+	}
+	return nil
+}
+
+// Kinds of TimeOff.
+const (
+	TimeOffBlock       = "block"
+	TimeOffHoliday     = "holiday"
+	TimeOffOutOfOffice = "out_of_office"
+)
+
+// TimeOff is a one-off period during which a person cannot be booked, even
+// inside their working hours.
+type TimeOff struct {
+	ID        int       `json:"id"`
+	OwnerRole string    `json:"owner_role"`
+	OwnerID   int       `json:"owner_id"`
+	Kind      string    `json:"kind"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Reason    string    `json:"reason"`
+}
+
+func (t TimeOff) owner() Participant {
+	return Participant{t.OwnerRole, t.OwnerID}
+}
+
+func (t *TimeOff) validate() error {
+	switch t.Kind {
+	case "":
+		t.Kind = TimeOffBlock
+	case TimeOffBlock, TimeOffHoliday, TimeOffOutOfOffice:
+	default:
+		return fmt.Errorf("kind must be one of %s, %s, %s", TimeOffBlock, TimeOffHoliday, TimeOffOutOfOffice)
+	}
+	if t.StartTime.IsZero() || t.EndTime.IsZero() {
+		return errors.New("start_time and end_time are required")
+	}
+	if !t.StartTime.Before(t.EndTime) {
+		return errors.New("start_time must be before end_time")
+	}
+	t.StartTime = t.StartTime.UTC().Truncate(time.Second)
+	t.EndTime = t.EndTime.UTC().Truncate(time.Second)
+	return nil
+}
+
+// interval is a half-open time range [start, end).
+type interval struct {
+	start, end time.Time
+}
+
+// workingWindows expands weekly working hours into the concrete windows
+// falling between from and to, evaluated in loc.
+func workingWindows(hours []WorkingHours, from, to time.Time, loc *time.Location) []interval {
+	var windows []interval
+	first := from.In(loc)
+	for day := time.Date(first.Year(), first.Month(), first.Day()-1, 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, wh := range hours {
+			if wh.Weekday != day.Weekday() {
+				continue
+			}
+			start := time.Date(day.Year(), day.Month(), day.Day(), 0, int(wh.StartTime), 0, 0, loc)
+			end := time.Date(day.Year(), day.Month(), day.Day(), 0, int(wh.EndTime), 0, 0, loc)
+			if start.Before(to) && end.After(from) {
+				windows = append(windows, interval{start, end})
+			}
+		}
+	}
+	return windows
+}
+
+// availabilityReader is what unavailability needs: availability entries and
+// the owners' time zones.
+type availabilityReader interface {
+	AvailabilityStore
+	peopleStore
+}
+
+// unavailability returns a Conflict for each participant who is not
+// available for the whole of [start, end): outside their working hours or
+// during time off. Only interviewers and HRs keep availability.
+func unavailability(ctx context.Context, st availabilityReader, participants []Participant, start, end time.Time) ([]Conflict, error) {
+	var conflicts []Conflict
+	for _, p := range participants {
+		if p.Role != RoleInterviewer && p.Role != RoleHR {
+			continue
+		}
+		working, err := withinWorkingHours(ctx, st, p, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if !working {
+			conflicts = append(conflicts, Conflict{
+				Role:          p.Role,
+				ParticipantID: p.ID,
+				ScheduledTime: start,
+				EndTime:       end,
+				Reason:        "outside working hours",
+			})
+		}
+
+		offs, err := st.ListTimeOff(ctx, p, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, off := range offs {
+			reason := strings.ReplaceAll(off.Kind, "_", " ")
+			if off.Reason != "" {
+				reason += " (" + off.Reason + ")"
+			}
+			conflicts = append(conflicts, Conflict{
+				Role:          p.Role,
+				ParticipantID: p.ID,
+				ScheduledTime: off.StartTime,
+				EndTime:       off.EndTime,
+				Reason:        reason,
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// mergeIntervals sorts intervals and joins the ones that overlap or touch,
+// so that working hours running past midnight into the next day's hours
+// form a single window.
+func mergeIntervals(intervals []interval) []interval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+	var merged []interval
+	for _, iv := range intervals {
+		if n := len(merged); n > 0 && !iv.start.After(merged[n-1].end) {
+			if iv.end.After(merged[n-1].end) {
+				merged[n-1].end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// withinWorkingHours reports whether [start, end) lies inside one of p's
+// working hours windows, evaluated in p's time zone. It is always true for
+// people without working hours.
+func withinWorkingHours(ctx context.Context, st availabilityReader, p Participant, start, end time.Time) (bool, error) {
+	hours, err := st.ListWorkingHours(ctx, p)
+	if err != nil || len(hours) == 0 {
+		return true, err
+	}
+	loc, err := participantLocation(ctx, st, p)
+	if err != nil {
+		return false, err
+	}
+	return withinWindows(mergeIntervals(workingWindows(hours, start, end, loc)), start, end), nil
+}
+
+// withinWindows reports whether a single window covers [start, end).
+func withinWindows(windows []interval, start, end time.Time) bool {
+	for _, w := range windows {
+		if !start.Before(w.start) && !end.After(w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// availabilityOwner resolves the {role}/{id} route variables to an existing
+// interviewer or HR, writing an error response when there is none.
+func (s *Server) availabilityOwner(w http.ResponseWriter, r *http.Request) (Participant, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return Participant{}, false
+	}
+
+	owner := Participant{Role: mux.Vars(r)["role"], ID: id}
+	var err error
+	switch owner.Role {
+	case RoleInterviewer:
+		_, err = s.interviewers.GetInterviewer(r.Context(), id)
+	case RoleHR:
+		_, err = s.hrs.GetHR(r.Context(), id)
+	default:
+		err = ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return Participant{}, false
+	}
+	return owner, true
+}
+
+// entryID parses the {entry} route variable.
+func entryID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["entry"])
+	if err != nil {
+		http.Error(w, "Invalid id: "+mux.Vars(r)["entry"], http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *Server) getAvailability(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.availabilityOwner(w, r)
+	if !ok {
+		return
+	}
+
+	hours, err := s.availability.ListWorkingHours(r.Context(), owner)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	offs, err := s.availability.ListTimeOff(r.Context(), owner, time.Time{}, time.Time{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"working_hours": hours,
+		"time_off":      offs,
+	})
+}
+
+func (s *Server) createWorkingHours(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.availabilityOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var wh WorkingHours
+	err := json.NewDecoder(r.Body).Decode(&wh)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	wh.OwnerRole, wh.OwnerID = owner.Role, owner.ID
+
+	if err := wh.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := s.availability.CreateWorkingHours(r.Context(), wh)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	SendResponse(map[string]any{"message": "Working Hours Created Successfully", "id": id}, w)
+}
+
+func (s *Server) updateWorkingHours(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.availabilityOwner(w, r)
+	if !ok {
+		return
+	}
+	id, ok := entryID(w, r)
+	if !ok {
+		return
+	}
+
+	var wh WorkingHours
+	err := json.NewDecoder(r.Body).Decode(&wh)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	wh.ID, wh.OwnerRole, wh.OwnerID = id, owner.Role, owner.ID
+
+	if err := wh.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.availability.UpdateWorkingHours(r.Context(), wh)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	SendResponse(map[string]string{"message": "Working Hours Updated Successfully"}, w)
+}
+
+func (s *Server) deleteWorkingHours(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.availabilityOwner(w, r)
+	if !ok {
+		return
+	}
+	id, ok := entryID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.availability.DeleteWorkingHours(r.Context(), owner, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	SendResponse(map[string]string{"message": "Working Hours Deleted Successfully"}, w)
+}
+
+func (s *Server) createTimeOff(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.availabilityOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var off TimeOff
+	err := json.NewDecoder(r.Body).Decode(&off)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	off.OwnerRole, off.OwnerID = owner.Role, owner.ID
+
+	if err := off.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := s.availability.CreateTimeOff(r.Context(), off)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	SendResponse(map[string]any{"message": "Time Off Created Successfully", "id": id}, w)
+}
+
+func (s *Server) updateTimeOff(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.availabilityOwner(w, r)
+	if !ok {
+		return
+	}
+	id, ok := entryID(w, r)
+	if !ok {
+		return
+	}
+
+	var off TimeOff
+	err := json.NewDecoder(r.Body).Decode(&off)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	off.ID, off.OwnerRole, off.OwnerID = id, owner.Role, owner.ID
+
+	if err := off.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.availability.UpdateTimeOff(r.Context(), off)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	SendResponse(map[string]string{"message": "Time Off Updated Successfully"}, w)
+}
+
+func (s *Server) deleteTimeOff(w http.ResponseWriter, r *http.Request) {
+	owner, ok := s.availabilityOwner(w, r)
+	if !ok {
+		return
+	}
+	id, ok := entryID(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.availability.DeleteTimeOff(r.Context(), owner, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	SendResponse(map[string]string{"message": "Time Off Deleted Successfully"}, w)
+}
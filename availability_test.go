@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// span is the interval between two RFC 3339 times.
+func span(start, end string) interval {
+	s, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		panic(err)
+	}
+	e, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		panic(err)
+	}
+	return interval{s, e}
+}
+
+func equalIntervals(a, b []interval) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].start.Equal(b[i].start) || !a[i].end.Equal(b[i].end) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWorkingWindows(t *testing.T) {
+	monday := []WorkingHours{{Weekday: time.Monday, StartTime: 9 * 60, EndTime: 17 * 60}}
+	tests := []struct {
+		name  string
+		hours []WorkingHours
+		zone  string
+		from  string
+		to    string
+		want  []interval
+	}{
+		{
+			name: "one day", hours: monday, zone: "UTC",
+			from: "2030-03-04T00:00:00Z", to: "2030-03-06T00:00:00Z",
+			want: []interval{span("2030-03-04T09:00:00Z", "2030-03-04T17:00:00Z")},
+		},
+		{
+			// Windows are not clipped to the range.
+			name: "inside a window", hours: monday, zone: "UTC",
+			from: "2030-03-04T12:00:00Z", to: "2030-03-04T13:00:00Z",
+			want: []interval{span("2030-03-04T09:00:00Z", "2030-03-04T17:00:00Z")},
+		},
+		{
+			name: "range ends as the window starts", hours: monday, zone: "UTC",
+			from: "2030-03-03T00:00:00Z", to: "2030-03-04T09:00:00Z",
+			want: nil,
+		},
+		{
+			name: "range starts as the window ends", hours: monday, zone: "UTC",
+			from: "2030-03-04T17:00:00Z", to: "2030-03-05T00:00:00Z",
+			want: nil,
+		},
+		{
+			// New York moves to daylight saving time on 10 March 2030, so
+			// the same local hours start an hour earlier in UTC.
+			name: "daylight saving change", hours: monday, zone: "America/New_York",
+			from: "2030-03-04T00:00:00Z", to: "2030-03-12T00:00:00Z",
+			want: []interval{
+				span("2030-03-04T14:00:00Z", "2030-03-04T22:00:00Z"),
+				span("2030-03-11T13:00:00Z", "2030-03-11T21:00:00Z"),
+			},
+		},
+		{
+			// Monday morning in Kolkata is still Sunday in UTC.
+			name: "zone ahead of UTC", zone: "Asia/Kolkata",
+			hours: []WorkingHours{{Weekday: time.Monday, StartTime: 0, EndTime: 2 * 60}},
+			from:  "2030-03-03T00:00:00Z", to: "2030-03-04T00:00:00Z",
+			want: []interval{span("2030-03-03T18:30:00Z", "2030-03-03T20:30:00Z")},
+		},
+		{
+			name: "until midnight", zone: "UTC",
+			hours: []WorkingHours{
+				{Weekday: time.Monday, StartTime: 22 * 60, EndTime: 24 * 60},
+				{Weekday: time.Tuesday, StartTime: 0, EndTime: 2 * 60},
+			},
+			from: "2030-03-04T00:00:00Z", to: "2030-03-06T00:00:00Z",
+			want: []interval{
+				span("2030-03-04T22:00:00Z", "2030-03-05T00:00:00Z"),
+				span("2030-03-05T00:00:00Z", "2030-03-05T02:00:00Z"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := span(tt.from, tt.to)
+			got := workingWindows(tt.hours, r.start, r.end, location(tt.zone))
+			if !equalIntervals(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeIntervals(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []interval
+		want []interval
+	}{
+		{"empty", nil, nil},
+		{
+			"touching",
+			[]interval{span("2030-03-05T00:00:00Z", "2030-03-05T02:00:00Z"), span("2030-03-04T22:00:00Z", "2030-03-05T00:00:00Z")},
+			[]interval{span("2030-03-04T22:00:00Z", "2030-03-05T02:00:00Z")},
+		},
+		{
+			"contained",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T17:00:00Z"), span("2030-03-04T10:00:00Z", "2030-03-04T11:00:00Z")},
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T17:00:00Z")},
+		},
+		{
+			"apart",
+			[]interval{span("2030-03-04T13:00:00Z", "2030-03-04T17:00:00Z"), span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z")},
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z"), span("2030-03-04T13:00:00Z", "2030-03-04T17:00:00Z")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeIntervals(tt.in); !equalIntervals(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinWindows(t *testing.T) {
+	windows := []interval{
+		span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z"),
+		span("2030-03-04T13:00:00Z", "2030-03-04T17:00:00Z"),
+	}
+	tests := []struct {
+		name string
+		slot interval
+		want bool
+	}{
+		{"inside", span("2030-03-04T10:00:00Z", "2030-03-04T11:00:00Z"), true},
+		{"exactly a window", span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z"), true},
+		{"ends at the end", span("2030-03-04T16:00:00Z", "2030-03-04T17:00:00Z"), true},
+		{"runs past the end", span("2030-03-04T16:30:00Z", "2030-03-04T17:30:00Z"), false},
+		{"spans the gap", span("2030-03-04T11:30:00Z", "2030-03-04T13:30:00Z"), false},
+		{"before any window", span("2030-03-04T08:00:00Z", "2030-03-04T09:00:00Z"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withinWindows(windows, tt.slot.start, tt.slot.end); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeTime(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	seedPeople(t, ctx, store)
+	interviewer := Participant{RoleInterviewer, 1}
+	hr := Participant{RoleHR, 1}
+	for _, wh := range []WorkingHours{
+		{OwnerRole: interviewer.Role, OwnerID: interviewer.ID, Weekday: time.Monday, StartTime: 9 * 60, EndTime: 17 * 60},
+		{OwnerRole: hr.Role, OwnerID: hr.ID, Weekday: time.Monday, StartTime: 10 * 60, EndTime: 18 * 60},
+	} {
+		if _, err := store.CreateWorkingHours(ctx, wh); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lunch := span("2030-03-04T12:00:00Z", "2030-03-04T13:00:00Z")
+	off := TimeOff{OwnerRole: interviewer.Role, OwnerID: interviewer.ID, Kind: TimeOffBlock, StartTime: lunch.start, EndTime: lunch.end}
+	if _, err := store.CreateTimeOff(ctx, off); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateInterview(ctx, testInterview(time.Date(2030, 3, 4, 15, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestServer(store)
+	s.scheduling.Buffer = 10 * time.Minute
+
+	day := span("2030-03-04T00:00:00Z", "2030-03-05T00:00:00Z")
+	got, err := s.freeTime(ctx, []Participant{interviewer, hr}, day.start, day.end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interval{
+		span("2030-03-04T10:00:00Z", "2030-03-04T12:00:00Z"),
+		span("2030-03-04T13:00:00Z", "2030-03-04T14:50:00Z"),
+		span("2030-03-04T16:10:00Z", "2030-03-04T17:00:00Z"),
+	}
+	if !equalIntervals(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
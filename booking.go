@@ -0,0 +1,527 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// minBookingSecret is the shortest booking.secret accepted, in bytes.
+const minBookingSecret = 32
+
+var (
+	// ErrInvalidLink is returned for tokens that are malformed or whose
+	// signature does not match.
+	ErrInvalidLink = errors.New("invalid link")
+	// ErrBookingLinkExpired is returned for tokens past their expiry time.
+	ErrBookingLinkExpired = errors.New("the booking link has expired")
+	// ErrBookingLinkUsed is returned when the link has already been used to
+	// book an interview.
+	ErrBookingLinkUsed = errors.New("the booking link has already been used")
+	// ErrSlotNotOffered is returned when a candidate picks a time the link
+	// does not offer.
+	ErrSlotNotOffered = errors.New("the booking link does not offer that time")
+)
+
+// BookingConfig holds the settings of the links candidates use to book,
+// reschedule and cancel interviews themselves.
+type BookingConfig struct {
+	// Secret signs the links. No links are handed out while it is empty.
+	Secret string `yaml:"secret"`
+	// LinkTTL is how long a booking link stays valid unless the request
+	// says otherwise.
+	LinkTTL time.Duration `yaml:"link_ttl"`
+	// BaseURL is prepended to the link paths, /booking/{token} and
+	// /manage/{token}.
+	BaseURL string `yaml:"base_url"`
+	// MaxReschedules is how many times a candidate may move an interview.
+	MaxReschedules int `yaml:"max_reschedules"`
+	// ChangeCutoff is how close to its start an interview can no longer be
+	// rescheduled or cancelled by the candidate.
+	ChangeCutoff time.Duration `yaml:"change_cutoff"`
+}
+
+func defaultBookingConfig() BookingConfig {
+	return BookingConfig{
+		LinkTTL:        72 * time.Hour,
+		MaxReschedules: 2,
+		ChangeCutoff:   12 * time.Hour,
+	}
+}
+
+// BookingLink is what a booking token carries. Everything needed to book is
+// in the token, so verifying it needs only the secret.
+type BookingLink struct {
+	ID              string      `json:"id"`
+	CandidateID     int         `json:"candidate_id"`
+	HRID            int         `json:"hr_id"`
+	InterviewerID   int         `json:"interviewer_id"`
+	Panel           []Panelist  `json:"panel,omitempty"`
+	DurationMinutes int         `json:"duration_minutes"`
+	Slots           []time.Time `json:"slots"`
+	ExpiresAt       time.Time   `json:"expires_at"`
+}
+
+// BookingRedemption records the interview booked through a link.
+type BookingRedemption struct {
+	LinkID      string    `json:"link_id"`
+	InterviewID int       `json:"interview_id"`
+	RedeemedAt  time.Time `json:"redeemed_at"`
+}
+
+// interview returns the interview booked when the candidate picks start.
+func (l BookingLink) interview(start time.Time) Interview {
+	return Interview{
+		InterviewerID:   l.InterviewerID,
+		CandidateID:     l.CandidateID,
+		HRID:            l.HRID,
+		ScheduledTime:   start,
+		DurationMinutes: l.DurationMinutes,
+		Panel:           append([]Panelist(nil), l.Panel...),
+	}
+}
+
+// offers reports whether start is one of the link's slots.
+func (l BookingLink) offers(start time.Time) bool {
+	for _, slot := range l.Slots {
+		if slot.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// signToken encodes v as a URL-safe token: the JSON payload and its
+// HMAC-SHA256, each base64url encoded and joined by a dot. The purpose is
+// part of the signed data, so a token issued for one purpose is rejected
+// for any other.
+func signToken(purpose string, v any, secret string) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(tokenMAC(purpose, encoded, secret)), nil
+}
+
+// verifyToken checks the token's signature and decodes its payload into v.
+// It fails with ErrInvalidLink.
+func verifyToken(purpose, token, secret string, v any) error {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidLink
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, tokenMAC(purpose, encoded, secret)) {
+		return ErrInvalidLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidLink
+	}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return ErrInvalidLink
+	}
+	return nil
+}
+
+func tokenMAC(purpose, encoded, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose + "\n" + encoded))
+	return mac.Sum(nil)
+}
+
+// verifyBookingLink checks the token's signature and expiry and returns the
+// link it carries.
+func verifyBookingLink(token, secret string, now time.Time) (BookingLink, error) {
+	var link BookingLink
+	if err := verifyToken("booking", token, secret, &link); err != nil {
+		return link, err
+	}
+	if !now.Before(link.ExpiresAt) {
+		return link, ErrBookingLinkExpired
+	}
+	return link, nil
+}
+
+// newBookingLinkID returns a random identifier for a link.
+func newBookingLinkID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// linkURL returns where the candidate opens a link with token, served under
+// /{path}/{token}.
+func (s *Server) linkURL(path, token string) string {
+	return strings.TrimSuffix(s.booking.BaseURL, "/") + "/" + path + "/" + token
+}
+
+// bookFromLink books the slot starting at start for the link's candidate and
+// marks the link as used, both in one atomic unit.
+func (s *Server) bookFromLink(ctx context.Context, link BookingLink, start time.Time) (Interview, []Conflict, error) {
+	if !link.offers(start) {
+		return Interview{}, nil, ErrSlotNotOffered
+	}
+	interview := link.interview(start)
+	if err := interview.normalizeTimes(s.scheduling.DefaultDuration); err != nil {
+		return Interview{}, nil, err
+	}
+
+	conflicts, err := s.bookWith(ctx, &interview, func(tx Store) error {
+		err := tx.RedeemBookingLink(ctx, BookingRedemption{
+			LinkID:      link.ID,
+			InterviewID: interview.ID,
+			RedeemedAt:  time.Now().UTC().Truncate(time.Second),
+		})
+		if errors.Is(err, ErrDuplicate) {
+			return ErrBookingLinkUsed
+		}
+		return err
+	})
+	return interview, conflicts, err
+}
+
+// bookingLinkRequest is the body of POST /candidate/{id}/booking-links. The
+// offered slots are either listed in slots or, when it is empty, suggested
+// from the participants' free time between from and to.
+type bookingLinkRequest struct {
+	HRID             int         `json:"hr_id"`
+	InterviewerID    int         `json:"interviewer_id"`
+	Panel            []Panelist  `json:"panel"`
+	DurationMinutes  int         `json:"duration_minutes"`
+	Slots            []time.Time `json:"slots"`
+	From             time.Time   `json:"from"`
+	To               time.Time   `json:"to"`
+	Limit            int         `json:"limit"`
+	ExpiresInMinutes int         `json:"expires_in_minutes"`
+}
+
+// offeredSlots returns the start times the link will offer, sorted and in
+// UTC.
+func (s *Server) offeredSlots(ctx context.Context, req bookingLinkRequest, participants []Participant, duration time.Duration, now time.Time) ([]time.Time, error) {
+	if len(req.Slots) > 0 {
+		if len(req.Slots) > maxSuggestions {
+			return nil, fmt.Errorf("at most %d slots can be offered", maxSuggestions)
+		}
+		slots := make([]time.Time, len(req.Slots))
+		for i, slot := range req.Slots {
+			if !slot.After(now) {
+				return nil, errors.New("slots must be in the future")
+			}
+			slots[i] = slot.UTC().Truncate(time.Second)
+		}
+		sort.Slice(slots, func(i, j int) bool { return slots[i].Before(slots[j]) })
+		return slots, nil
+	}
+
+	from, to := req.From.UTC(), req.To.UTC()
+	if from.IsZero() || from.Before(now) {
+		from = now
+	}
+	if to.IsZero() {
+		to = from.Add(7 * 24 * time.Hour)
+	}
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	if to.Sub(from) > maxSuggestionRange {
+		return nil, fmt.Errorf("from and to cannot be more than %d days apart", maxSuggestionRange/(24*time.Hour))
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSuggestions
+	}
+	if limit < 1 || limit > maxSuggestions {
+		return nil, fmt.Errorf("limit must be a number between 1 and %d", maxSuggestions)
+	}
+
+	free, err := s.freeTime(ctx, participants, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var slots []time.Time
+	for _, suggestion := range suggestSlots(free, duration, limit) {
+		slots = append(slots, suggestion.ScheduledTime)
+	}
+	if len(slots) == 0 {
+		return nil, errors.New("the participants have no free slot between from and to")
+	}
+	return slots, nil
+}
+
+func (s *Server) createBookingLink(w http.ResponseWriter, r *http.Request) {
+	if s.booking.Secret == "" {
+		http.Error(w, "Booking links are disabled: booking.secret is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	candidateID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req bookingLinkRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if (req.InterviewerID == 0 && len(req.Panel) == 0) || req.HRID == 0 {
+		http.Error(w, "Interviewer or HR ID is missing or zero", http.StatusBadRequest)
+		return
+	}
+
+	link := BookingLink{CandidateID: candidateID}
+	template := Interview{InterviewerID: req.InterviewerID, Panel: req.Panel, HRID: req.HRID, CandidateID: candidateID}
+	err = template.normalizePanel()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	link.InterviewerID, link.Panel, link.HRID = template.InterviewerID, template.Panel, template.HRID
+
+	link.DurationMinutes = req.DurationMinutes
+	if link.DurationMinutes == 0 {
+		link.DurationMinutes = int(s.scheduling.DefaultDuration / time.Minute)
+	}
+	duration := time.Duration(link.DurationMinutes) * time.Minute
+	if duration < time.Minute || duration > maxInterviewDuration {
+		http.Error(w, fmt.Sprintf("duration_minutes must be between 1 and %d", maxInterviewDuration/time.Minute), http.StatusBadRequest)
+		return
+	}
+
+	ttl := s.booking.LinkTTL
+	if req.ExpiresInMinutes < 0 {
+		http.Error(w, "expires_in_minutes must not be negative", http.StatusBadRequest)
+		return
+	}
+	if req.ExpiresInMinutes > 0 {
+		ttl = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+
+	err = s.checkParticipants(r.Context(), template.participants())
+	if err != nil {
+		if errors.Is(err, ErrUnknownParticipant) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	now := time.Now().UTC()
+	link.Slots, err = s.offeredSlots(r.Context(), req, template.participants(), duration, now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	link.ExpiresAt = now.Add(ttl).Truncate(time.Second)
+
+	link.ID, err = newBookingLinkID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	token, err := signToken("booking", link, s.booking.Secret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	SendResponse(map[string]any{
+		"message":    "Booking Link Created Successfully",
+		"token":      token,
+		"url":        s.linkURL("booking", token),
+		"expires_at": link.ExpiresAt,
+		"slots":      link.Slots,
+	}, w)
+}
+
+// bookingLinkFromRequest verifies the {token} route variable, writing the
+// error response when it is not a usable link.
+func (s *Server) bookingLinkFromRequest(w http.ResponseWriter, r *http.Request) (BookingLink, bool) {
+	if s.booking.Secret == "" {
+		http.NotFound(w, r)
+		return BookingLink{}, false
+	}
+	link, err := verifyBookingLink(mux.Vars(r)["token"], s.booking.Secret, time.Now())
+	switch {
+	case errors.Is(err, ErrInvalidLink):
+		http.NotFound(w, r)
+		return link, false
+	case errors.Is(err, ErrBookingLinkExpired):
+		http.Error(w, err.Error(), http.StatusGone)
+		return link, false
+	}
+
+	_, err = s.bookings.GetBookingRedemption(r.Context(), link.ID)
+	if err == nil {
+		http.Error(w, ErrBookingLinkUsed.Error(), http.StatusGone)
+		return link, false
+	}
+	if !errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return link, false
+	}
+	return link, true
+}
+
+// bookingSlot is an offered slot as shown to the candidate.
+type bookingSlot struct {
+	ScheduledTime time.Time `json:"scheduled_time"`
+	EndTime       time.Time `json:"end_time"`
+	Available     bool      `json:"available"`
+}
+
+func (s *Server) getBookingLink(w http.ResponseWriter, r *http.Request) {
+	link, ok := s.bookingLinkFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	candidate, err := s.candidates.GetCandidate(r.Context(), link.CandidateID)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc := location(candidate.TimeZone)
+
+	// Slots taken since the link was created are shown as unavailable.
+	now := time.Now()
+	slots := make([]bookingSlot, len(link.Slots))
+	participants := link.interview(time.Time{}).participants()
+	err = s.tx.Atomically(r.Context(), participants, func(tx Store) error {
+		for i, start := range link.Slots {
+			interview := link.interview(start)
+			interview.EndTime = start.Add(interview.Duration())
+			slots[i] = bookingSlot{ScheduledTime: start.In(loc), EndTime: interview.EndTime.In(loc)}
+			if !start.After(now) {
+				continue
+			}
+			conflicts, err := s.checkSlot(r.Context(), tx, participants, interview, 0)
+			if err != nil {
+				return err
+			}
+			slots[i].Available = len(conflicts) == 0
+		}
+		return nil
+	})
+	if errors.Is(err, ErrUnknownParticipant) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"candidate_name":   candidate.Name,
+		"duration_minutes": link.DurationMinutes,
+		"time_zone":        candidate.TimeZone,
+		"expires_at":       link.ExpiresAt.In(loc),
+		"slots":            slots,
+	})
+}
+
+// bookingRequest is the body of POST /booking/{token}.
+type bookingRequest struct {
+	ScheduledTime time.Time `json:"scheduled_time"`
+}
+
+func (s *Server) bookSlot(w http.ResponseWriter, r *http.Request) {
+	link, ok := s.bookingLinkFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req bookingRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !req.ScheduledTime.After(time.Now()) {
+		http.Error(w, "scheduled_time must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	interview, conflicts, err := s.bookFromLink(r.Context(), link, req.ScheduledTime)
+	switch {
+	case errors.Is(err, ErrBookingLinkUsed):
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	case errors.Is(err, ErrUnknownParticipant):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, ErrSlotNotOffered):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// The candidate is not told who else is busy, only that the slot is gone.
+	if len(conflicts) > 0 {
+		http.Error(w, "That time is no longer available, please pick another one", http.StatusConflict)
+		return
+	}
+
+	hr, err := s.hrs.GetHR(r.Context(), interview.HRID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	candidate, err := s.candidates.GetCandidate(r.Context(), interview.CandidateID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = s.notifyPanel(r.Context(), interview, EventScheduled, "You have an interview scheduled at ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = s.notify(r.Context(), hr.recipient(), candidate.Name+" booked an interview at "+localTime(interview.ScheduledTime, hr.TimeZone)+" "+interview.InterviewLink, interviewMetadata(EventScheduled, interview))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = s.notify(r.Context(), candidate.recipient(), "You have an interview scheduled at "+localTime(interview.ScheduledTime, candidate.TimeZone)+" "+interview.InterviewLink+s.manageNote(interview), interviewMetadata(EventScheduled, interview))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	SendResponse(map[string]any{
+		"message":        "Interview Booked Successfully",
+		"scheduled_time": interview.ScheduledTime.In(location(candidate.TimeZone)),
+		"end_time":       interview.EndTime.In(location(candidate.TimeZone)),
+	}, w)
+}
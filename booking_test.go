@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBookFromUsedLink(t *testing.T) {
+	link := BookingLink{
+		ID:              "link-1",
+		CandidateID:     1,
+		HRID:            1,
+		InterviewerID:   1,
+		Panel:           []Panelist{{1, PanelLead}},
+		DurationMinutes: 60,
+		Slots:           []time.Time{testTime, testTime.Add(3 * time.Hour)},
+	}
+
+	for _, backend := range storeBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.open(t)
+			seedPeople(t, ctx, store)
+			s, _ := newTestServer(store)
+
+			first, conflicts, err := s.bookFromLink(ctx, link, link.Slots[0])
+			if err != nil || len(conflicts) > 0 {
+				t.Fatalf("booking the first slot: %v %v", err, conflicts)
+			}
+			_, _, err = s.bookFromLink(ctx, link, link.Slots[1])
+			if !errors.Is(err, ErrBookingLinkUsed) {
+				t.Fatalf("booking with a used link: got %v, want ErrBookingLinkUsed", err)
+			}
+
+			interviews, err := store.ListInterviews(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(interviews) != 1 || interviews[0].ID != first.ID {
+				t.Errorf("got interviews %+v, want only %d", interviews, first.ID)
+			}
+			redemption, err := store.GetBookingRedemption(ctx, link.ID)
+			if err != nil || redemption.InterviewID != first.ID {
+				t.Errorf("got redemption %+v (%v), want interview %d", redemption, err, first.ID)
+			}
+		})
+	}
+}
+
+func TestMemoryAtomicallyRollsBack(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	seedPeople(t, ctx, store)
+
+	failed := errors.New("failed")
+	err := store.Atomically(ctx, []Participant{{RoleInterviewer, 1}}, func(tx Store) error {
+		id, err := tx.CreateInterview(ctx, testInterview(testTime))
+		if err != nil {
+			return err
+		}
+		err = tx.SetInterviewStatus(ctx, StatusChange{InterviewID: id, FromStatus: StatusScheduled, ToStatus: StatusCancelled, CreatedAt: testTime})
+		if err != nil {
+			return err
+		}
+		if err := tx.AddAssignment(ctx, Assignment{InterviewID: id, InterviewerID: 1, CreatedAt: testTime}); err != nil {
+			return err
+		}
+		if err := tx.DeleteInterviewer(ctx, 2); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("got %v, want the callback's error", err)
+	}
+
+	if interviews, _ := store.ListInterviews(ctx); len(interviews) != 0 {
+		t.Errorf("got interviews %+v after rolling back, want none", interviews)
+	}
+	if changes, _ := store.ListStatusChanges(ctx, 1); len(changes) != 0 {
+		t.Errorf("got status changes %+v after rolling back, want none", changes)
+	}
+	if _, err := store.GetAssignment(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got assignment after rolling back (%v), want ErrNotFound", err)
+	}
+	if _, err := store.GetInterviewer(ctx, 2); err != nil {
+		t.Errorf("interviewer 2 is gone after rolling back: %v", err)
+	}
+}
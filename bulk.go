@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxBulkItems bounds how many interviews one bulk request may create.
+const maxBulkItems = 200
+
+// Bulk item states.
+const (
+	BulkCreated  = "created"
+	BulkConflict = "conflict"
+	// BulkSkipped marks a free item left out because the request was
+	// all_or_nothing and another item had conflicts or no slot.
+	BulkSkipped = "skipped"
+	// BulkNoSlot marks a candidate for whom the recurrence rule ran out of
+	// occurrences.
+	BulkNoSlot = "no_slot"
+)
+
+// bulkRequest is the body of POST /interviews/bulk. Candidates are given
+// consecutive slots of duration_minutes starting at start_time, separated
+// by gap_minutes, or the occurrences of recurrence when it is set. A single
+// candidate with a recurrence gets one interview per occurrence.
+type bulkRequest struct {
+	InterviewerID   int        `json:"interviewer_id"`
+	Panel           []Panelist `json:"panel"`
+	HRID            int        `json:"hr_id"`
+	CandidateIDs    []int      `json:"candidate_ids"`
+	StartTime       time.Time  `json:"start_time"`
+	DurationMinutes int        `json:"duration_minutes"`
+	// GapMinutes defaults to the scheduling buffer.
+	GapMinutes *int   `json:"gap_minutes"`
+	Recurrence string `json:"recurrence"`
+	// TimeZone is the zone the recurrence is evaluated in; it defaults to
+	// the lead interviewer's.
+	TimeZone     string `json:"time_zone"`
+	AllOrNothing bool   `json:"all_or_nothing"`
+}
+
+// bulkResult reports what happened to one item of a bulk request.
+type bulkResult struct {
+	Index         int        `json:"index"`
+	CandidateID   int        `json:"candidate_id"`
+	ScheduledTime time.Time  `json:"scheduled_time,omitempty"`
+	EndTime       time.Time  `json:"end_time,omitempty"`
+	Status        string     `json:"status"`
+	InterviewID   int        `json:"interview_id,omitempty"`
+	Conflicts     []Conflict `json:"conflicts,omitempty"`
+}
+
+// slotTimes works out the start time of every item of req.
+func (s *Server) slotTimes(ctx context.Context, req bulkRequest, lead int) ([]time.Time, error) {
+	length := time.Duration(req.DurationMinutes) * time.Minute
+	if req.Recurrence == "" {
+		gap := s.scheduling.Buffer
+		if req.GapMinutes != nil {
+			if *req.GapMinutes < 0 {
+				return nil, errors.New("gap_minutes must not be negative")
+			}
+			gap = time.Duration(*req.GapMinutes) * time.Minute
+		}
+		times := make([]time.Time, len(req.CandidateIDs))
+		for i := range times {
+			times[i] = req.StartTime.Add(time.Duration(i) * (length + gap))
+		}
+		return times, nil
+	}
+
+	rule, err := parseRecurrence(req.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := participantLocation(ctx, s.people(), Participant{RoleInterviewer, lead})
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: Interviewer %d does not exist", ErrUnknownParticipant, lead)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.TimeZone != "" {
+		zone, err := normalizeTimeZone(req.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		loc = location(zone)
+	}
+
+	want := len(req.CandidateIDs)
+	if want == 1 {
+		if !rule.bounded() {
+			return nil, errors.New("a recurrence for a single candidate needs COUNT or UNTIL")
+		}
+		want = maxBulkItems
+	}
+	return rule.occurrences(req.StartTime, loc, want), nil
+}
+
+// bookBulk books items in one atomic unit. Items are checked in order, each
+// against the stored interviews and the items accepted before it; free items
+// are created unless allOrNothing is set and another item has conflicts.
+// unplaced counts the further items of the request that got no slot at all;
+// under allOrNothing they too keep the free items from being created.
+func (s *Server) bookBulk(ctx context.Context, items []Interview, unplaced int, allOrNothing bool) ([]bulkResult, error) {
+	var participants []Participant
+	for _, item := range items {
+		participants = append(participants, item.participants()...)
+	}
+
+	results := make([]bulkResult, len(items))
+	err := s.tx.Atomically(ctx, participants, func(tx Store) error {
+		var accepted []int
+		for i, item := range items {
+			results[i] = bulkResult{Index: i, CandidateID: item.CandidateID, ScheduledTime: item.ScheduledTime, EndTime: item.EndTime}
+
+			conflicts, err := s.checkSlot(ctx, tx, item.participants(), item, 0)
+			if err != nil {
+				return err
+			}
+			start, end := s.bookingWindow(item)
+			for _, j := range accepted {
+				for _, c := range conflictsWith(items[j], item.participants(), start, end) {
+					c.Reason = fmt.Sprintf("overlaps item %d of this request", j)
+					conflicts = append(conflicts, c)
+				}
+			}
+
+			if len(conflicts) > 0 {
+				results[i].Status, results[i].Conflicts = BulkConflict, conflicts
+				continue
+			}
+			accepted = append(accepted, i)
+		}
+
+		if allOrNothing && (len(accepted) < len(items) || unplaced > 0) {
+			for _, i := range accepted {
+				results[i].Status = BulkSkipped
+			}
+			return nil
+		}
+		for _, i := range accepted {
+			items[i].Status = StatusScheduled
+			id, err := tx.CreateInterview(ctx, items[i])
+			if err != nil {
+				return err
+			}
+			items[i].ID = id
+			results[i].Status, results[i].InterviewID = BulkCreated, id
+		}
+		return nil
+	})
+	return results, err
+}
+
+func (s *Server) createInterviewsBulk(w http.ResponseWriter, r *http.Request) {
+	var req bulkRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.HRID == 0 || len(req.CandidateIDs) == 0 || req.StartTime.IsZero() {
+		http.Error(w, "hr_id, candidate_ids and start_time are required", http.StatusBadRequest)
+		return
+	}
+	if len(req.CandidateIDs) > maxBulkItems {
+		http.Error(w, fmt.Sprintf("At most %d candidates can be scheduled at once", maxBulkItems), http.StatusBadRequest)
+		return
+	}
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = int(s.scheduling.DefaultDuration / time.Minute)
+	}
+
+	template := Interview{InterviewerID: req.InterviewerID, Panel: req.Panel, HRID: req.HRID}
+	err = template.normalizePanel()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	times, err := s.slotTimes(r.Context(), req, template.InterviewerID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var items []Interview
+	var noSlot []bulkResult
+	for i, start := range times {
+		item := template
+		item.Panel = append([]Panelist(nil), template.Panel...)
+		item.CandidateID = req.CandidateIDs[i%len(req.CandidateIDs)]
+		item.ScheduledTime, item.DurationMinutes = start, req.DurationMinutes
+		if err := item.normalizeTimes(s.scheduling.DefaultDuration); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		items = append(items, item)
+	}
+	for i := len(times); i < len(req.CandidateIDs); i++ {
+		noSlot = append(noSlot, bulkResult{Index: i, CandidateID: req.CandidateIDs[i], Status: BulkNoSlot})
+	}
+	if len(items) == 0 {
+		http.Error(w, "The recurrence has no occurrences", http.StatusBadRequest)
+		return
+	}
+
+	results, err := s.bookBulk(r.Context(), items, len(noSlot), req.AllOrNothing)
+	if err != nil {
+		if errors.Is(err, ErrUnknownParticipant) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	results = append(results, noSlot...)
+
+	created := 0
+	for _, result := range results {
+		if result.Status == BulkCreated {
+			created++
+		}
+	}
+	if created == 0 {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]any{
+			"message": "No interviews were created",
+			"created": 0,
+			"results": results,
+		})
+		return
+	}
+
+	hr, err := s.hrs.GetHR(r.Context(), req.HRID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, item := range items {
+		if item.ID == 0 {
+			continue
+		}
+		err = s.notifyPanel(r.Context(), item, EventScheduled, "You have an interview scheduled at ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = s.notify(r.Context(), hr.recipient(), "You have an interview scheduled at "+localTime(item.ScheduledTime, hr.TimeZone)+" "+item.InterviewLink, interviewMetadata(EventScheduled, item))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		candidate, err := s.candidates.GetCandidate(r.Context(), item.CandidateID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		err = s.notify(r.Context(), candidate.recipient(), "You have an interview scheduled at "+localTime(item.ScheduledTime, candidate.TimeZone)+" "+item.InterviewLink+s.manageNote(item), interviewMetadata(EventScheduled, item))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	SendResponse(map[string]any{
+		"message": fmt.Sprintf("%d of %d Interviews Created Successfully", created, len(results)),
+		"created": created,
+		"results": results,
+	}, w)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBulkNoSlot(t *testing.T) {
+	// The rule has one occurrence for two candidates, so candidate 2 gets
+	// no slot.
+	const request = `{"interviewer_id": 1, "hr_id": 1, "candidate_ids": [1, 2], "start_time": "2030-03-04T10:00:00Z", "recurrence": "FREQ=DAILY;COUNT=1", "all_or_nothing": %s}`
+
+	tests := []struct {
+		allOrNothing string
+		wantCode     int
+		wantCreated  int
+	}{
+		{"true", http.StatusConflict, 0},
+		{"false", http.StatusOK, 1},
+	}
+	for _, tt := range tests {
+		t.Run("all_or_nothing="+tt.allOrNothing, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemoryStore()
+			seedPeople(t, ctx, store)
+			s, sent := newTestServer(store)
+
+			w := httptest.NewRecorder()
+			body := fmt.Sprintf(request, tt.allOrNothing)
+			s.routes().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/interviews/bulk", strings.NewReader(body)))
+			if w.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			var response struct {
+				Created int `json:"created"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+
+			interviews, err := store.ListInterviews(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.Created != tt.wantCreated || len(interviews) != tt.wantCreated {
+				t.Errorf("reported %d created and stored %d, want %d", response.Created, len(interviews), tt.wantCreated)
+			}
+			if tt.wantCreated == 0 && len(*sent) > 0 {
+				t.Errorf("sent %d notifications for a request that created nothing", len(*sent))
+			}
+		})
+	}
+}
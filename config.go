@@ -0,0 +1,348 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/database"
+	"github.com/go-sql-driver/mysql"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting the server needs at startup. Values are
+// resolved in increasing order of precedence: built-in defaults, the
+// optional YAML config file, environment variables and command-line flags.
+type Config struct {
+	ListenAddr string           `yaml:"listen_addr"`
+	Store      string           `yaml:"store"`
+	Database   database.Config  `yaml:"database"`
+	PubSub     PubSubConfig     `yaml:"pubsub"`
+	Twilio     TwilioConfig     `yaml:"twilio"`
+	Scheduling SchedulingConfig `yaml:"scheduling"`
+	Booking    BookingConfig    `yaml:"booking"`
+	Admin      AdminConfig      `yaml:"admin"`
+	SMTP       SMTPConfig       `yaml:"smtp"`
+}
+
+// setting binds a config key to the field it populates. The key doubles as
+// the name used in error messages; the environment variable and flag names
+// are derived from it.
+type setting struct {
+	key      string
+	usage    string
+	required bool
+	value    value
+}
+
+// value is a typed view of a Config field that can be set from text.
+type value interface {
+	Set(string) error
+	String() string
+}
+
+type stringValue struct{ p *string }
+
+func (v stringValue) Set(s string) error { *v.p = s; return nil }
+func (v stringValue) String() string     { return *v.p }
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a boolean", s)
+	}
+	*v.p = b
+	return nil
+}
+
+func (v boolValue) String() string { return strconv.FormatBool(*v.p) }
+
+type intValue struct{ p *int }
+
+func (v intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", s)
+	}
+	*v.p = n
+	return nil
+}
+
+func (v intValue) String() string { return strconv.Itoa(*v.p) }
+
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as 30s or 5m", s)
+	}
+	*v.p = d
+	return nil
+}
+
+func (v durationValue) String() string { return v.p.String() }
+
+// flagRecorder captures a flag's raw text so it can be applied after the
+// config file and environment variables.
+type flagRecorder struct {
+	values map[string]string
+	key    string
+	isBool bool
+}
+
+func (f flagRecorder) Set(s string) error { f.values[f.key] = s; return nil }
+func (f flagRecorder) String() string     { return "" }
+func (f flagRecorder) IsBoolFlag() bool   { return f.isBool }
+
+func (s setting) env() string {
+	return "INTERVIEW_" + strings.ToUpper(strings.NewReplacer(".", "_").Replace(s.key))
+}
+
+func (s setting) flag() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+func defaultConfig() Config {
+	return Config{
+		ListenAddr: ":8000",
+		Store:      "mysql",
+		Database:   database.DefaultConfig(),
+		Scheduling: defaultSchedulingConfig(),
+		Booking:    defaultBookingConfig(),
+		SMTP:       defaultSMTPConfig(),
+		PubSub: PubSubConfig{
+			TopicID:        "notification_topic",
+			SubscriptionID: "notification_subscription",
+		},
+	}
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"listen_addr", "address the HTTP server listens on", true, stringValue{&c.ListenAddr}},
+		{"store", "persistence backend: mysql, sqlite or memory", true, stringValue{&c.Store}},
+		{"database.dsn", "MySQL data source name or SQLite database file, required by the mysql and sqlite stores", false, stringValue{&c.Database.DSN}},
+		{"database.auto_migrate", "apply pending schema migrations on startup", false, boolValue{&c.Database.AutoMigrate}},
+		{"database.max_open_conns", "maximum number of open database connections", false, intValue{&c.Database.MaxOpenConns}},
+		{"database.max_idle_conns", "maximum number of idle database connections", false, intValue{&c.Database.MaxIdleConns}},
+		{"database.conn_max_lifetime", "maximum time a database connection is reused", false, durationValue{&c.Database.ConnMaxLifetime}},
+		{"database.connect_retries", "times to retry the initial database ping", false, intValue{&c.Database.ConnectRetries}},
+		{"database.retry_backoff", "wait before the first database ping retry, doubled after each retry", false, durationValue{&c.Database.RetryBackoff}},
+		{"pubsub.project_id", "Google Cloud project hosting the notification topic", true, stringValue{&c.PubSub.ProjectID}},
+		{"pubsub.topic_id", "Pub/Sub topic notifications are published to", true, stringValue{&c.PubSub.TopicID}},
+		{"pubsub.subscription_id", "Pub/Sub subscription the SMS sender reads from", true, stringValue{&c.PubSub.SubscriptionID}},
+		{"pubsub.credentials_file", "service account key file, exported as GOOGLE_APPLICATION_CREDENTIALS", false, stringValue{&c.PubSub.CredentialsFile}},
+		{"twilio.account_sid", "Twilio account SID", true, stringValue{&c.Twilio.AccountSID}},
+		{"twilio.auth_token", "Twilio auth token", true, stringValue{&c.Twilio.AuthToken}},
+		{"twilio.phone_number", "Twilio number SMS are sent from", true, stringValue{&c.Twilio.PhoneNumber}},
+		{"scheduling.default_duration", "interview length used when a request gives none", false, durationValue{&c.Scheduling.DefaultDuration}},
+		{"scheduling.buffer", "minimum gap between two interviews of the same participant", false, durationValue{&c.Scheduling.Buffer}},
+		{"scheduling.assignment_strategy", "how interviewers are assigned automatically: " + strings.Join(strategyNames(), ", "), true, stringValue{&c.Scheduling.AssignmentStrategy}},
+		{"scheduling.delete_policy", "what deleting a person does to their upcoming interviews: " + strings.Join(deletePolicies, ", "), true, stringValue{&c.Scheduling.DeletePolicy}},
+		{"booking.secret", "key signing candidate booking and manage links; the links are disabled while it is empty", false, stringValue{&c.Booking.Secret}},
+		{"booking.link_ttl", "how long a booking link stays valid by default", false, durationValue{&c.Booking.LinkTTL}},
+		{"booking.base_url", "public URL of the service, used to build candidate links", false, stringValue{&c.Booking.BaseURL}},
+		{"booking.max_reschedules", "how many times a candidate may reschedule an interview", false, intValue{&c.Booking.MaxReschedules}},
+		{"booking.change_cutoff", "how long before an interview candidates can no longer reschedule or cancel it", false, durationValue{&c.Booking.ChangeCutoff}},
+		{"admin.token", "bearer token required by the /admin endpoints, which are disabled while it is empty", false, stringValue{&c.Admin.Token}},
+		{"smtp.host", "SMTP server of the email notification channel, which is disabled while it is empty", false, stringValue{&c.SMTP.Host}},
+		{"smtp.port", "SMTP server port", false, intValue{&c.SMTP.Port}},
+		{"smtp.username", "SMTP username; no auth is attempted while it is empty", false, stringValue{&c.SMTP.Username}},
+		{"smtp.password", "SMTP password", false, stringValue{&c.SMTP.Password}},
+		{"smtp.from", "sender of notification emails, required with smtp.host", false, stringValue{&c.SMTP.From}},
+		{"smtp.starttls", "require STARTTLS before authenticating and sending", false, boolValue{&c.SMTP.StartTLS}},
+		{"smtp.timeout", "how long one email delivery may take", false, durationValue{&c.SMTP.Timeout}},
+	}
+}
+
+// loadConfig resolves the configuration from args and the environment and
+// validates it. The returned error lists every missing or invalid key.
+func loadConfig(args []string) (Config, error) {
+	cfg, problems, err := resolveConfig(args)
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, configError(problems)
+}
+
+// loadDatabaseConfig is loadConfig for commands that only touch the
+// database, such as migrate: problems with unrelated keys are ignored.
+func loadDatabaseConfig(args []string) (Config, error) {
+	cfg, problems, err := resolveConfig(args)
+	if err != nil {
+		return cfg, err
+	}
+
+	var relevant []string
+	for _, p := range problems {
+		if strings.HasPrefix(p, "store ") || strings.HasPrefix(p, "database.") {
+			relevant = append(relevant, p)
+		}
+	}
+	return cfg, configError(relevant)
+}
+
+// resolveConfig applies the config file, environment and flags on top of the
+// defaults. Problems with individual keys are returned separately from
+// errors that prevent resolving the configuration at all. Every problem
+// starts with the key it concerns.
+func resolveConfig(args []string) (Config, []string, error) {
+	cfg := defaultConfig()
+	settings := cfg.settings()
+
+	fs := flag.NewFlagSet("interview-system", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("INTERVIEW_CONFIG"), "path to a YAML config file")
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		_, isBool := s.value.(boolValue)
+		fs.Var(flagRecorder{flagValues, s.key, isBool}, s.flag(), fmt.Sprintf("%s (env %s)", s.usage, s.env()))
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, nil, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return cfg, nil, err
+		}
+	}
+
+	var problems []string
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env()); ok {
+			if err := s.value.Set(v); err != nil {
+				problems = append(problems, fmt.Sprintf("%s is invalid (env %s): %v", s.key, s.env(), err))
+			}
+		}
+		if v, ok := flagValues[s.key]; ok {
+			if err := s.value.Set(v); err != nil {
+				problems = append(problems, fmt.Sprintf("%s is invalid (flag -%s): %v", s.key, s.flag(), err))
+			}
+		}
+	}
+
+	return cfg, append(problems, cfg.problems()...), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %v", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// problems validates the resolved configuration.
+func (c *Config) problems() []string {
+	var problems []string
+	for _, s := range c.settings() {
+		if s.required && strings.TrimSpace(s.value.String()) == "" {
+			problems = append(problems, fmt.Sprintf("%s is required (flag -%s, env %s)", s.key, s.flag(), s.env()))
+		}
+	}
+
+	if c.ListenAddr != "" {
+		if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+			problems = append(problems, fmt.Sprintf("listen_addr is invalid: %v", err))
+		}
+	}
+	switch c.Store {
+	case "", "memory":
+	case "mysql":
+		if c.Database.DSN == "" {
+			problems = append(problems, "database.dsn is required by the mysql store (flag -database-dsn, env INTERVIEW_DATABASE_DSN)")
+		} else if dsn, err := mysql.ParseDSN(c.Database.DSN); err != nil {
+			problems = append(problems, fmt.Sprintf("database.dsn is invalid: %v", err))
+		} else if !dsn.ParseTime {
+			problems = append(problems, "database.dsn is invalid: parseTime=true is required")
+		} else if dsn.Loc != time.UTC {
+			problems = append(problems, "database.dsn is invalid: loc must be UTC (the default) so that times are stored in UTC")
+		}
+	case "sqlite":
+		if c.Database.DSN == "" {
+			problems = append(problems, "database.dsn is required by the sqlite store (flag -database-dsn, env INTERVIEW_DATABASE_DSN)")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("store is invalid: %q is not one of mysql, sqlite, memory", c.Store))
+	}
+	if c.Database.MaxOpenConns < 0 {
+		problems = append(problems, "database.max_open_conns is invalid: must not be negative")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		problems = append(problems, "database.max_idle_conns is invalid: must not be negative")
+	}
+	if c.Database.ConnectRetries < 0 {
+		problems = append(problems, "database.connect_retries is invalid: must not be negative")
+	}
+	if c.Scheduling.DefaultDuration < time.Minute || c.Scheduling.DefaultDuration > maxInterviewDuration || c.Scheduling.DefaultDuration%time.Minute != 0 {
+		problems = append(problems, fmt.Sprintf("scheduling.default_duration is invalid: must be a whole number of minutes between 1m and %s", maxInterviewDuration))
+	}
+	if c.Scheduling.Buffer < 0 {
+		problems = append(problems, "scheduling.buffer is invalid: must not be negative")
+	}
+	if _, ok := assignmentStrategies[c.Scheduling.AssignmentStrategy]; !ok && c.Scheduling.AssignmentStrategy != "" {
+		problems = append(problems, fmt.Sprintf("scheduling.assignment_strategy is invalid: %q is not one of %s", c.Scheduling.AssignmentStrategy, strings.Join(strategyNames(), ", ")))
+	}
+	switch c.Scheduling.DeletePolicy {
+	case DeleteBlock, DeleteCascade, DeleteReassign:
+	default:
+		problems = append(problems, fmt.Sprintf("scheduling.delete_policy is invalid: %q is not one of %s", c.Scheduling.DeletePolicy, strings.Join(deletePolicies, ", ")))
+	}
+	if c.Booking.Secret != "" && len(c.Booking.Secret) < minBookingSecret {
+		problems = append(problems, fmt.Sprintf("booking.secret is invalid: must be at least %d bytes long", minBookingSecret))
+	}
+	if c.Booking.LinkTTL <= 0 {
+		problems = append(problems, "booking.link_ttl is invalid: must be positive")
+	}
+	if c.Booking.MaxReschedules < 0 {
+		problems = append(problems, "booking.max_reschedules is invalid: must not be negative")
+	}
+	if c.Booking.ChangeCutoff < 0 {
+		problems = append(problems, "booking.change_cutoff is invalid: must not be negative")
+	}
+	if c.Admin.Token != "" && len(c.Admin.Token) < minAdminToken {
+		problems = append(problems, fmt.Sprintf("admin.token is invalid: must be at least %d bytes long", minAdminToken))
+	}
+	if c.SMTP.Host != "" {
+		if _, err := mail.ParseAddress(c.SMTP.From); err != nil {
+			problems = append(problems, fmt.Sprintf("smtp.from is invalid: %q is not an email address", c.SMTP.From))
+		}
+		if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+			problems = append(problems, "smtp.port is invalid: must be between 1 and 65535")
+		}
+		if c.SMTP.Timeout <= 0 {
+			problems = append(problems, "smtp.timeout is invalid: must be positive")
+		}
+	}
+	if c.Twilio.PhoneNumber != "" && !strings.HasPrefix(c.Twilio.PhoneNumber, "+") {
+		problems = append(problems, "twilio.phone_number is invalid: must be in E.164 format, e.g. +13156311532")
+	}
+
+	return problems
+}
+
+func configError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+}
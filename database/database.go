@@ -1,34 +1,169 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/mattn/go-sqlite3"
 )
 
-var db *sql.DB
+// Config describes how to reach the database and how to size the
+// connection pool.
+type Config struct {
+	DSN string `yaml:"dsn"`
+	// AutoMigrate applies pending migrations when the server starts.
+	AutoMigrate bool `yaml:"auto_migrate"`
 
-func ConnectDB() (*sql.DB, error) {
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
 
-	dsn := "root:Sanju2001@/interview_system?charset=utf8&parseTime=True&loc=Local"
+	// ConnectRetries is how many times the initial ping is retried, waiting
+	// RetryBackoff before the first retry and doubling it after each one.
+	ConnectRetries int           `yaml:"connect_retries"`
+	RetryBackoff   time.Duration `yaml:"retry_backoff"`
+}
 
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to the database: %v", err)
+// DefaultConfig returns the pool settings used when none are configured.
+func DefaultConfig() Config {
+	return Config{
+		MaxOpenConns:    25,
+		MaxIdleConns:    25,
+		ConnMaxLifetime: 5 * time.Minute,
+		ConnectRetries:  5,
+		RetryBackoff:    time.Second,
 	}
+}
 
-	// Test the database connection
-	err = db.Ping()
+// maxRetryBackoff caps the wait between connection attempts.
+const maxRetryBackoff = 30 * time.Second
+
+var drivers = map[string]string{
+	"mysql":  "mysql",
+	"sqlite": "sqlite3",
+}
+
+var (
+	mu sync.Mutex
+	db *sql.DB
+)
+
+// Connect opens the shared connection pool for dialect ("mysql" or
+// "sqlite") and waits until the database answers a ping. Later calls return
+// the pool opened by the first successful one.
+func Connect(dialect string, cfg Config) (*sql.DB, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if db != nil {
+		return db, nil
+	}
+
+	pool, err := Open(dialect, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to ping the database: %v", err)
+		return nil, err
 	}
 
 	fmt.Println("Connected to the database!")
 
+	db = pool
 	return db, nil
 }
 
+// Open opens a new connection pool for dialect, separate from the shared
+// one, and waits until the database answers a ping. The caller closes it.
+func Open(dialect string, cfg Config) (*sql.DB, error) {
+	driver, ok := drivers[dialect]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database dialect %q", dialect)
+	}
+
+	dsn := cfg.DSN
+	if dialect == "sqlite" && !strings.Contains(dsn, "_txlock=") {
+		// Take the write lock when a transaction begins rather than at its
+		// first write, so that read-then-write transactions serialise instead
+		// of failing with SQLITE_BUSY.
+		if strings.Contains(dsn, "?") {
+			dsn += "&_txlock=immediate"
+		} else {
+			dsn += "?_txlock=immediate"
+		}
+	}
+
+	pool, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the database: %v", err)
+	}
+
+	pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	if dialect == "sqlite" {
+		// SQLite allows a single writer; serialising access through one
+		// connection avoids "database is locked" errors under concurrent
+		// requests.
+		pool.SetMaxOpenConns(1)
+	}
+
+	// Test the database connection
+	if err := ping(pool, cfg); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+func ping(pool *sql.DB, cfg Config) error {
+	backoff := cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := pool.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return fmt.Errorf("failed to ping the database after %d attempts: %v", attempt+1, err)
+		}
+
+		log.Printf("Database not reachable (%v), retrying in %s", err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// ConnectDB opens the shared MySQL connection pool.
+func ConnectDB(cfg Config) (*sql.DB, error) {
+	return Connect("mysql", cfg)
+}
+
+// ConnectToDB returns the shared connection pool, or nil before Connect has
+// succeeded.
 func ConnectToDB() *sql.DB {
+	mu.Lock()
+	defer mu.Unlock()
+
 	return db
 }
+
+// Close closes the shared connection pool.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if db == nil {
+		return nil
+	}
+	err := db.Close()
+	db = nil
+	return err
+}
@@ -0,0 +1,269 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration files live in migrations/<dialect>/ and are named
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations returns the embedded migrations for dialect ("mysql" or
+// "sqlite") ordered by version.
+func Migrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		file := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(file, "."+direction+".sql")
+		versionText, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s", file)
+		}
+
+		body, err := fs.ReadFile(migrationFiles, path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	var migrations []Migration
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration and returns the ones applied.
+func MigrateUp(ctx context.Context, db *sql.DB, dialect string) ([]Migration, error) {
+	var applied []Migration
+	err := withMigrationLock(ctx, db, dialect, func(conn *sql.Conn) error {
+		status, err := migrationStatus(ctx, conn, dialect)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			if s.AppliedAt != nil {
+				continue
+			}
+			if err := runMigration(ctx, conn, s.Migration, s.Up,
+				"INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+				s.Version, s.Name, time.Now().UTC()); err != nil {
+				return err
+			}
+			applied = append(applied, s.Migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown rolls back the most recently applied migration. It returns
+// nil when nothing is applied.
+func MigrateDown(ctx context.Context, db *sql.DB, dialect string) (*Migration, error) {
+	var rolledBack *Migration
+	err := withMigrationLock(ctx, db, dialect, func(conn *sql.Conn) error {
+		status, err := migrationStatus(ctx, conn, dialect)
+		if err != nil {
+			return err
+		}
+		for i := len(status) - 1; i >= 0; i-- {
+			s := status[i]
+			if s.AppliedAt == nil {
+				continue
+			}
+			if s.Down == "" {
+				return fmt.Errorf("migration %04d_%s cannot be rolled back: no down script", s.Version, s.Name)
+			}
+			if err := runMigration(ctx, conn, s.Migration, s.Down,
+				"DELETE FROM schema_version WHERE version = ?", s.Version); err != nil {
+				return err
+			}
+			rolledBack = &s.Migration
+			return nil
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists every known migration and when it was applied.
+func Status(ctx context.Context, db *sql.DB, dialect string) ([]MigrationStatus, error) {
+	var status []MigrationStatus
+	err := withMigrationLock(ctx, db, dialect, func(conn *sql.Conn) error {
+		var err error
+		status, err = migrationStatus(ctx, conn, dialect)
+		return err
+	})
+	return status, err
+}
+
+// withMigrationLock runs fn on a dedicated connection after making sure the
+// schema_version table exists. On MySQL it also holds a named lock so that
+// several instances auto-migrating on boot do not race each other.
+func withMigrationLock(ctx context.Context, db *sql.DB, dialect string, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if dialect == "mysql" {
+		var locked sql.NullInt64
+		err := conn.QueryRowContext(ctx, "SELECT GET_LOCK('interview_system_migrations', 60)").Scan(&locked)
+		if err != nil {
+			return fmt.Errorf("failed to acquire the migration lock: %v", err)
+		}
+		if locked.Int64 != 1 {
+			return fmt.Errorf("timed out waiting for the migration lock")
+		}
+		defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK('interview_system_migrations')")
+	}
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_version (
+    version    INT          NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP    NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create the schema_version table: %v", err)
+	}
+
+	return fn(conn)
+}
+
+func migrationStatus(ctx context.Context, conn *sql.Conn, dialect string) ([]MigrationStatus, error) {
+	migrations, err := Migrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appliedAt := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		status[i].Migration = m
+		if at, ok := appliedAt[m.Version]; ok {
+			status[i].AppliedAt = &at
+		}
+	}
+	return status, nil
+}
+
+// runMigration executes script statement by statement followed by the
+// schema_version bookkeeping query. The statements share a transaction,
+// although MySQL commits DDL implicitly.
+func runMigration(ctx context.Context, conn *sql.Conn, m Migration, script, bookkeeping string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %v", m.Version, m.Name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %v", m.Version, m.Name, err)
+	}
+	return tx.Commit()
+}
+
+// splitStatements splits a migration script on semicolons that end a line,
+// dropping "--" comment lines. The MySQL driver does not accept several
+// statements in one Exec unless multiStatements is enabled in the DSN.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
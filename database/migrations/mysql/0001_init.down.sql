@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS interview;
+DROP TABLE IF EXISTS hr;
+DROP TABLE IF EXISTS candidate;
+DROP TABLE IF EXISTS interviewer;
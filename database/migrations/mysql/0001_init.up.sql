@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS interviewer (
+    id           INT          NOT NULL AUTO_INCREMENT,
+    name         VARCHAR(255) NOT NULL,
+    phone_number VARCHAR(20)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY interviewer_phone_number (phone_number)
+);
+
+CREATE TABLE IF NOT EXISTS candidate (
+    id           INT          NOT NULL AUTO_INCREMENT,
+    name         VARCHAR(255) NOT NULL,
+    phone_number VARCHAR(20)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY candidate_phone_number (phone_number)
+);
+
+CREATE TABLE IF NOT EXISTS hr (
+    id           INT          NOT NULL AUTO_INCREMENT,
+    name         VARCHAR(255) NOT NULL,
+    phone_number VARCHAR(20)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY hr_phone_number (phone_number)
+);
+
+CREATE TABLE IF NOT EXISTS interview (
+    id             INT          NOT NULL AUTO_INCREMENT,
+    interviewer_id INT          NOT NULL,
+    candidate_id   INT          NOT NULL,
+    hr_id          INT          NOT NULL,
+    scheduled_time VARCHAR(64)  NOT NULL,
+    rescheduled    BOOLEAN      NOT NULL DEFAULT FALSE,
+    interview_link VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (id),
+    KEY interview_interviewer_time (interviewer_id, scheduled_time),
+    KEY interview_candidate_time (candidate_id, scheduled_time),
+    KEY interview_hr_time (hr_id, scheduled_time)
+);
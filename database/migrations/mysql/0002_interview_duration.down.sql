@@ -0,0 +1,3 @@
+ALTER TABLE interview
+    DROP COLUMN duration_minutes,
+    MODIFY scheduled_time VARCHAR(64) NOT NULL;
@@ -0,0 +1,6 @@
+-- scheduled_time becomes a real timestamp (stored in UTC) and interviews
+-- gain a length so that overlapping ranges can be detected. Existing values
+-- must already be in a format MySQL can convert, e.g. 2023-06-20 10:00:00.
+ALTER TABLE interview
+    MODIFY scheduled_time DATETIME NOT NULL,
+    ADD COLUMN duration_minutes INT NOT NULL DEFAULT 60 AFTER scheduled_time;
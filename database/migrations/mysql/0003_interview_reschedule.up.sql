@@ -0,0 +1,13 @@
+CREATE TABLE interview_reschedule (
+    id                        INT          NOT NULL AUTO_INCREMENT,
+    interview_id              INT          NOT NULL,
+    previous_time             DATETIME     NOT NULL,
+    previous_duration_minutes INT          NOT NULL,
+    new_time                  DATETIME     NOT NULL,
+    new_duration_minutes      INT          NOT NULL,
+    reason                    VARCHAR(512) NOT NULL DEFAULT '',
+    actor                     VARCHAR(255) NOT NULL DEFAULT '',
+    created_at                DATETIME     NOT NULL,
+    PRIMARY KEY (id),
+    KEY interview_reschedule_interview (interview_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE time_off;
+DROP TABLE working_hours;
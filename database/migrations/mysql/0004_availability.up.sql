@@ -0,0 +1,22 @@
+CREATE TABLE working_hours (
+    id           INT         NOT NULL AUTO_INCREMENT,
+    owner_role   VARCHAR(32) NOT NULL,
+    owner_id     INT         NOT NULL,
+    weekday      TINYINT     NOT NULL,
+    start_minute SMALLINT    NOT NULL,
+    end_minute   SMALLINT    NOT NULL,
+    PRIMARY KEY (id),
+    KEY working_hours_owner (owner_role, owner_id)
+);
+
+CREATE TABLE time_off (
+    id         INT          NOT NULL AUTO_INCREMENT,
+    owner_role VARCHAR(32)  NOT NULL,
+    owner_id   INT          NOT NULL,
+    kind       VARCHAR(32)  NOT NULL,
+    start_time DATETIME     NOT NULL,
+    end_time   DATETIME     NOT NULL,
+    reason     VARCHAR(512) NOT NULL DEFAULT '',
+    PRIMARY KEY (id),
+    KEY time_off_owner_time (owner_role, owner_id, start_time)
+);
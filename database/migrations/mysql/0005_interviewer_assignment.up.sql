@@ -0,0 +1,17 @@
+CREATE TABLE interviewer_skill (
+    interviewer_id INT          NOT NULL,
+    skill          VARCHAR(64)  NOT NULL,
+    PRIMARY KEY (interviewer_id, skill),
+    KEY interviewer_skill_skill (skill)
+);
+
+CREATE TABLE interview_assignment (
+    interview_id   INT          NOT NULL,
+    interviewer_id INT          NOT NULL,
+    strategy       VARCHAR(32)  NOT NULL,
+    skills         VARCHAR(512) NOT NULL DEFAULT '',
+    reason         VARCHAR(512) NOT NULL DEFAULT '',
+    created_at     DATETIME     NOT NULL,
+    PRIMARY KEY (interview_id),
+    KEY interview_assignment_strategy (strategy, created_at)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE interview_panelist (
+    interview_id   INT         NOT NULL,
+    interviewer_id INT         NOT NULL,
+    panel_role     VARCHAR(32) NOT NULL,
+    PRIMARY KEY (interview_id, interviewer_id),
+    KEY interview_panelist_interviewer (interviewer_id)
+);
+
+INSERT INTO interview_panelist (interview_id, interviewer_id, panel_role)
+SELECT id, interviewer_id, 'lead' FROM interview;
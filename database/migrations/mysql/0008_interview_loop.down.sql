@@ -0,0 +1,2 @@
+DROP TABLE interview_loop_round;
+DROP TABLE interview_loop;
@@ -0,0 +1,19 @@
+CREATE TABLE interview_loop (
+    id           INT          NOT NULL AUTO_INCREMENT,
+    candidate_id INT          NOT NULL,
+    hr_id        INT          NOT NULL,
+    name         VARCHAR(255) NOT NULL DEFAULT '',
+    same_day     BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at   DATETIME     NOT NULL,
+    PRIMARY KEY (id),
+    KEY interview_loop_candidate (candidate_id)
+);
+
+CREATE TABLE interview_loop_round (
+    loop_id      INT          NOT NULL,
+    position     INT          NOT NULL,
+    name         VARCHAR(255) NOT NULL DEFAULT '',
+    interview_id INT          NOT NULL,
+    PRIMARY KEY (loop_id, position),
+    UNIQUE KEY interview_loop_round_interview (interview_id)
+);
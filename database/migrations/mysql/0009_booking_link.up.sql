@@ -0,0 +1,7 @@
+CREATE TABLE booking_link_redemption (
+    link_id      VARCHAR(64) NOT NULL,
+    interview_id INT         NOT NULL,
+    redeemed_at  DATETIME    NOT NULL,
+    PRIMARY KEY (link_id),
+    KEY booking_link_redemption_interview (interview_id)
+);
@@ -0,0 +1,3 @@
+DROP TABLE interview_status_change;
+DROP INDEX interview_status ON interview;
+ALTER TABLE interview DROP COLUMN status;
@@ -0,0 +1,14 @@
+ALTER TABLE interview ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'scheduled';
+CREATE INDEX interview_status ON interview (status);
+
+CREATE TABLE interview_status_change (
+    id           INT          NOT NULL AUTO_INCREMENT,
+    interview_id INT          NOT NULL,
+    from_status  VARCHAR(16)  NOT NULL,
+    to_status    VARCHAR(16)  NOT NULL,
+    reason       VARCHAR(512) NOT NULL DEFAULT '',
+    actor        VARCHAR(255) NOT NULL DEFAULT '',
+    created_at   DATETIME     NOT NULL,
+    PRIMARY KEY (id),
+    KEY interview_status_change_interview (interview_id)
+);
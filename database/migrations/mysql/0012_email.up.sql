@@ -0,0 +1,3 @@
+ALTER TABLE interviewer ADD COLUMN email VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE candidate ADD COLUMN email VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE hr ADD COLUMN email VARCHAR(255) NOT NULL DEFAULT '';
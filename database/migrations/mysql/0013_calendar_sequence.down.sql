@@ -0,0 +1 @@
+ALTER TABLE interview DROP COLUMN calendar_sequence;
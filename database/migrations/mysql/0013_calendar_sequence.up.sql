@@ -0,0 +1 @@
+ALTER TABLE interview ADD COLUMN calendar_sequence INT NOT NULL DEFAULT 0;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS interviewer (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         TEXT    NOT NULL,
+    phone_number TEXT    NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS candidate (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         TEXT    NOT NULL,
+    phone_number TEXT    NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS hr (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         TEXT    NOT NULL,
+    phone_number TEXT    NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS interview (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    interviewer_id INTEGER NOT NULL,
+    candidate_id   INTEGER NOT NULL,
+    hr_id          INTEGER NOT NULL,
+    scheduled_time TEXT    NOT NULL,
+    rescheduled    BOOLEAN NOT NULL DEFAULT 0,
+    interview_link TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS interview_interviewer_time ON interview (interviewer_id, scheduled_time);
+CREATE INDEX IF NOT EXISTS interview_candidate_time ON interview (candidate_id, scheduled_time);
+CREATE INDEX IF NOT EXISTS interview_hr_time ON interview (hr_id, scheduled_time);
@@ -0,0 +1,20 @@
+CREATE TABLE interview_old (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    interviewer_id INTEGER NOT NULL,
+    candidate_id   INTEGER NOT NULL,
+    hr_id          INTEGER NOT NULL,
+    scheduled_time TEXT    NOT NULL,
+    rescheduled    BOOLEAN NOT NULL DEFAULT 0,
+    interview_link TEXT    NOT NULL DEFAULT ''
+);
+
+INSERT INTO interview_old (id, interviewer_id, candidate_id, hr_id, scheduled_time, rescheduled, interview_link)
+SELECT id, interviewer_id, candidate_id, hr_id, scheduled_time, rescheduled, interview_link FROM interview;
+
+DROP TABLE interview;
+
+ALTER TABLE interview_old RENAME TO interview;
+
+CREATE INDEX interview_interviewer_time ON interview (interviewer_id, scheduled_time);
+CREATE INDEX interview_candidate_time ON interview (candidate_id, scheduled_time);
+CREATE INDEX interview_hr_time ON interview (hr_id, scheduled_time);
@@ -0,0 +1,23 @@
+-- SQLite cannot change a column's type in place, so the interview table is
+-- rebuilt with scheduled_time declared as DATETIME and a duration column.
+CREATE TABLE interview_new (
+    id               INTEGER  PRIMARY KEY AUTOINCREMENT,
+    interviewer_id   INTEGER  NOT NULL,
+    candidate_id     INTEGER  NOT NULL,
+    hr_id            INTEGER  NOT NULL,
+    scheduled_time   DATETIME NOT NULL,
+    duration_minutes INTEGER  NOT NULL DEFAULT 60,
+    rescheduled      BOOLEAN  NOT NULL DEFAULT 0,
+    interview_link   TEXT     NOT NULL DEFAULT ''
+);
+
+INSERT INTO interview_new (id, interviewer_id, candidate_id, hr_id, scheduled_time, rescheduled, interview_link)
+SELECT id, interviewer_id, candidate_id, hr_id, scheduled_time, rescheduled, interview_link FROM interview;
+
+DROP TABLE interview;
+
+ALTER TABLE interview_new RENAME TO interview;
+
+CREATE INDEX interview_interviewer_time ON interview (interviewer_id, scheduled_time);
+CREATE INDEX interview_candidate_time ON interview (candidate_id, scheduled_time);
+CREATE INDEX interview_hr_time ON interview (hr_id, scheduled_time);
@@ -0,0 +1 @@
+DROP TABLE interview_reschedule;
@@ -0,0 +1,13 @@
+CREATE TABLE interview_reschedule (
+    id                        INTEGER  PRIMARY KEY AUTOINCREMENT,
+    interview_id              INTEGER  NOT NULL,
+    previous_time             DATETIME NOT NULL,
+    previous_duration_minutes INTEGER  NOT NULL,
+    new_time                  DATETIME NOT NULL,
+    new_duration_minutes      INTEGER  NOT NULL,
+    reason                    TEXT     NOT NULL DEFAULT '',
+    actor                     TEXT     NOT NULL DEFAULT '',
+    created_at                DATETIME NOT NULL
+);
+
+CREATE INDEX interview_reschedule_interview ON interview_reschedule (interview_id);
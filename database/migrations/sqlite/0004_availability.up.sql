@@ -0,0 +1,22 @@
+CREATE TABLE working_hours (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_role   TEXT    NOT NULL,
+    owner_id     INTEGER NOT NULL,
+    weekday      INTEGER NOT NULL,
+    start_minute INTEGER NOT NULL,
+    end_minute   INTEGER NOT NULL
+);
+
+CREATE INDEX working_hours_owner ON working_hours (owner_role, owner_id);
+
+CREATE TABLE time_off (
+    id         INTEGER  PRIMARY KEY AUTOINCREMENT,
+    owner_role TEXT     NOT NULL,
+    owner_id   INTEGER  NOT NULL,
+    kind       TEXT     NOT NULL,
+    start_time DATETIME NOT NULL,
+    end_time   DATETIME NOT NULL,
+    reason     TEXT     NOT NULL DEFAULT ''
+);
+
+CREATE INDEX time_off_owner_time ON time_off (owner_role, owner_id, start_time);
@@ -0,0 +1,2 @@
+DROP TABLE interview_assignment;
+DROP TABLE interviewer_skill;
@@ -0,0 +1,18 @@
+CREATE TABLE interviewer_skill (
+    interviewer_id INTEGER NOT NULL,
+    skill          TEXT    NOT NULL,
+    PRIMARY KEY (interviewer_id, skill)
+);
+
+CREATE INDEX interviewer_skill_skill ON interviewer_skill (skill);
+
+CREATE TABLE interview_assignment (
+    interview_id   INTEGER  PRIMARY KEY,
+    interviewer_id INTEGER  NOT NULL,
+    strategy       TEXT     NOT NULL,
+    skills         TEXT     NOT NULL DEFAULT '',
+    reason         TEXT     NOT NULL DEFAULT '',
+    created_at     DATETIME NOT NULL
+);
+
+CREATE INDEX interview_assignment_strategy ON interview_assignment (strategy, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE hr DROP COLUMN time_zone;
+ALTER TABLE candidate DROP COLUMN time_zone;
+ALTER TABLE interviewer DROP COLUMN time_zone;
@@ -0,0 +1,3 @@
+ALTER TABLE interviewer ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE candidate ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE hr ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,11 @@
+CREATE TABLE interview_panelist (
+    interview_id   INTEGER NOT NULL,
+    interviewer_id INTEGER NOT NULL,
+    panel_role     TEXT    NOT NULL,
+    PRIMARY KEY (interview_id, interviewer_id)
+);
+
+CREATE INDEX interview_panelist_interviewer ON interview_panelist (interviewer_id);
+
+INSERT INTO interview_panelist (interview_id, interviewer_id, panel_role)
+SELECT id, interviewer_id, 'lead' FROM interview;
@@ -0,0 +1,18 @@
+CREATE TABLE interview_loop (
+    id           INTEGER  PRIMARY KEY AUTOINCREMENT,
+    candidate_id INTEGER  NOT NULL,
+    hr_id        INTEGER  NOT NULL,
+    name         TEXT     NOT NULL DEFAULT '',
+    same_day     BOOLEAN  NOT NULL DEFAULT 0,
+    created_at   DATETIME NOT NULL
+);
+
+CREATE INDEX interview_loop_candidate ON interview_loop (candidate_id);
+
+CREATE TABLE interview_loop_round (
+    loop_id      INTEGER NOT NULL,
+    position     INTEGER NOT NULL,
+    name         TEXT    NOT NULL DEFAULT '',
+    interview_id INTEGER NOT NULL UNIQUE,
+    PRIMARY KEY (loop_id, position)
+);
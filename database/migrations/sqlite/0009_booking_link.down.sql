@@ -0,0 +1 @@
+DROP TABLE booking_link_redemption;
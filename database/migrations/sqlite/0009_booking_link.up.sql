@@ -0,0 +1,7 @@
+CREATE TABLE booking_link_redemption (
+    link_id      TEXT     PRIMARY KEY,
+    interview_id INTEGER  NOT NULL,
+    redeemed_at  DATETIME NOT NULL
+);
+
+CREATE INDEX booking_link_redemption_interview ON booking_link_redemption (interview_id);
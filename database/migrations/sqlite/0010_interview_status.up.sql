@@ -0,0 +1,14 @@
+ALTER TABLE interview ADD COLUMN status TEXT NOT NULL DEFAULT 'scheduled';
+CREATE INDEX interview_status ON interview (status);
+
+CREATE TABLE interview_status_change (
+    id           INTEGER  PRIMARY KEY AUTOINCREMENT,
+    interview_id INTEGER  NOT NULL,
+    from_status  TEXT     NOT NULL,
+    to_status    TEXT     NOT NULL,
+    reason       TEXT     NOT NULL DEFAULT '',
+    actor        TEXT     NOT NULL DEFAULT '',
+    created_at   DATETIME NOT NULL
+);
+
+CREATE INDEX interview_status_change_interview ON interview_status_change (interview_id);
@@ -0,0 +1,3 @@
+ALTER TABLE hr DROP COLUMN notification_channels;
+ALTER TABLE candidate DROP COLUMN notification_channels;
+ALTER TABLE interviewer DROP COLUMN notification_channels;
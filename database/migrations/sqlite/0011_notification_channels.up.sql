@@ -0,0 +1,3 @@
+ALTER TABLE interviewer ADD COLUMN notification_channels TEXT NOT NULL DEFAULT 'sms';
+ALTER TABLE candidate ADD COLUMN notification_channels TEXT NOT NULL DEFAULT 'sms';
+ALTER TABLE hr ADD COLUMN notification_channels TEXT NOT NULL DEFAULT 'sms';
@@ -0,0 +1,3 @@
+ALTER TABLE hr DROP COLUMN email;
+ALTER TABLE candidate DROP COLUMN email;
+ALTER TABLE interviewer DROP COLUMN email;
@@ -0,0 +1,3 @@
+ALTER TABLE interviewer ADD COLUMN email TEXT NOT NULL DEFAULT '';
+ALTER TABLE candidate ADD COLUMN email TEXT NOT NULL DEFAULT '';
+ALTER TABLE hr ADD COLUMN email TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE interview ADD COLUMN calendar_sequence INTEGER NOT NULL DEFAULT 0;
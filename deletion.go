@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delete policies decide what happens to the upcoming interviews of a person
+// being deleted.
+const (
+	// DeleteBlock refuses to delete someone who has upcoming interviews.
+	DeleteBlock = "block"
+	// DeleteCascade cancels their upcoming interviews.
+	DeleteCascade = "cascade"
+	// DeleteReassign hands their upcoming interviews to another interviewer
+	// or HR.
+	DeleteReassign = "reassign"
+)
+
+var deletePolicies = []string{DeleteBlock, DeleteCascade, DeleteReassign}
+
+// ErrHasInterviews is returned by the block policy when the person still
+// has upcoming interviews.
+var ErrHasInterviews = errors.New("has upcoming interviews")
+
+// withParticipant returns a copy of the interview in which p is replaced by
+// the person with id to, in the same role. An interviewer replaced as lead
+// is replaced as lead; a replacement already on the panel keeps their place.
+func (i Interview) withParticipant(p Participant, to int) Interview {
+	switch p.Role {
+	case RoleCandidate:
+		i.CandidateID = to
+	case RoleHR:
+		i.HRID = to
+	case RoleInterviewer:
+		if i.InterviewerID == p.ID {
+			i.Panel = i.panelWithLead(to)
+			i.InterviewerID = to
+			break
+		}
+		var panel []Panelist
+		for _, q := range i.Panel {
+			switch {
+			case q.InterviewerID != p.ID:
+				panel = append(panel, q)
+			case !i.onPanel(to):
+				panel = append(panel, Panelist{to, q.Role})
+			}
+		}
+		i.Panel = panel
+	}
+	return i
+}
+
+// deletion reports what deleting a person did to their upcoming interviews.
+type deletion struct {
+	// Affected lists the upcoming interviews as they were before.
+	Affected []Interview
+	// Updated lists them as they are after, reassigned or cancelled.
+	Updated []Interview
+}
+
+// deleteParticipant deletes p, applying policy to their upcoming interviews
+// in the same atomic unit. reassignTo is the person, in p's role, who takes
+// over under the reassign policy. It fails with ErrHasInterviews under the
+// block policy, and returns the conflicts of the replacement when they
+// cannot take over every interview; nothing changes in either case.
+func (s *Server) deleteParticipant(ctx context.Context, p Participant, policy string, reassignTo int, reason string) (deletion, []Conflict, error) {
+	participants := []Participant{p}
+	if policy == DeleteReassign {
+		participants = append(participants, Participant{p.Role, reassignTo})
+	}
+
+	var result deletion
+	var conflicts []Conflict
+	now := time.Now().UTC().Truncate(time.Second)
+	err := s.tx.Atomically(ctx, participants, func(tx Store) error {
+		interviews, err := tx.ListInterviews(ctx, StatusScheduled, StatusConfirmed, StatusInProgress)
+		if err != nil {
+			return err
+		}
+		for _, interview := range interviews {
+			// Interviews that have already ended are history, whatever
+			// their status says.
+			if interview.hasParticipant(p) && interview.EndTime.After(now) {
+				result.Affected = append(result.Affected, interview)
+			}
+		}
+
+		// Everything is checked before anything is written.
+		switch policy {
+		case DeleteBlock:
+			if len(result.Affected) > 0 {
+				return ErrHasInterviews
+			}
+		case DeleteCascade:
+			for _, interview := range result.Affected {
+				if !interview.open() {
+					return fmt.Errorf("%w: interview %d is %s", ErrInvalidTransition, interview.ID, interview.Status)
+				}
+			}
+		case DeleteReassign:
+			replacement := Participant{p.Role, reassignTo}
+			for _, interview := range result.Affected {
+				updated := interview.withParticipant(p, reassignTo)
+				c, err := s.checkSlot(ctx, tx, []Participant{replacement}, updated, interview.ID)
+				if err != nil {
+					return err
+				}
+				conflicts = append(conflicts, c...)
+				result.Updated = append(result.Updated, updated)
+			}
+			if len(conflicts) > 0 {
+				return nil
+			}
+		}
+
+		for i, interview := range result.Affected {
+			switch policy {
+			case DeleteCascade:
+				err = tx.SetInterviewStatus(ctx, StatusChange{
+					InterviewID: interview.ID,
+					FromStatus:  interview.Status,
+					ToStatus:    StatusCancelled,
+					Reason:      reason,
+					CreatedAt:   now,
+				})
+				interview.Status = StatusCancelled
+				result.Updated = append(result.Updated, interview)
+			case DeleteReassign:
+				err = tx.UpdateInterview(ctx, interview.ID, result.Updated[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		switch p.Role {
+		case RoleInterviewer:
+			return tx.DeleteInterviewer(ctx, p.ID)
+		case RoleCandidate:
+			return tx.DeleteCandidate(ctx, p.ID)
+		default:
+			return tx.DeleteHR(ctx, p.ID)
+		}
+	})
+	return result, conflicts, err
+}
+
+// deletePerson is the DELETE handler for interviewers, candidates and HRs.
+// The policy query parameter overrides scheduling.delete_policy, and
+// reassign_to names who takes over under the reassign policy.
+func (s *Server) deletePerson(w http.ResponseWriter, r *http.Request, role string) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	p := Participant{role, id}
+
+	query := r.URL.Query()
+	policy := s.scheduling.DeletePolicy
+	if text := query.Get("policy"); text != "" {
+		policy = text
+	}
+	var reassignTo int
+	switch policy {
+	case DeleteBlock, DeleteCascade:
+	case DeleteReassign:
+		if role == RoleCandidate {
+			http.Error(w, "Interviews cannot be reassigned to another candidate", http.StatusBadRequest)
+			return
+		}
+		var err error
+		reassignTo, err = strconv.Atoi(query.Get("reassign_to"))
+		if err != nil || reassignTo == id {
+			http.Error(w, "reassign_to must be the id of another "+strings.ToLower(roleTitle(role)), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, fmt.Sprintf("policy must be one of %s", strings.Join(deletePolicies, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	err := s.checkParticipants(r.Context(), []Participant{p})
+	if errors.Is(err, ErrUnknownParticipant) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reason := fmt.Sprintf("%s %d is no longer available", roleTitle(role), id)
+	result, conflicts, err := s.deleteParticipant(r.Context(), p, policy, reassignTo, reason)
+	switch {
+	case errors.Is(err, ErrHasInterviews):
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]any{
+			"message":    fmt.Sprintf("%s %d has %d upcoming interviews; delete with policy=cascade or policy=reassign", roleTitle(role), id, len(result.Affected)),
+			"interviews": result.Affected,
+		})
+		return
+	case errors.Is(err, ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, ErrUnknownParticipant):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(conflicts) > 0 {
+		sendConflictsMessage(w, fmt.Sprintf("%s %d cannot take over every interview", roleTitle(role), reassignTo), conflicts)
+		return
+	}
+
+	ids := make([]int, len(result.Updated))
+	for i, interview := range result.Updated {
+		ids[i] = interview.ID
+		switch policy {
+		case DeleteCascade:
+			err = s.notifyCancelled(r.Context(), interview, reason)
+		case DeleteReassign:
+			err = s.notifyReassigned(r.Context(), interview, Participant{role, reassignTo})
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response := map[string]any{"message": roleTitle(role) + " was deleted Successfully"}
+	switch policy {
+	case DeleteCascade:
+		response["cancelled"] = ids
+	case DeleteReassign:
+		response["reassigned"] = ids
+	}
+	SendResponse(response, w)
+}
+
+// notifyReassigned tells the person who took over interview about it.
+func (s *Server) notifyReassigned(ctx context.Context, interview Interview, p Participant) error {
+	message := func(zone string) string {
+		return "You have been assigned an interview scheduled at " + localTime(interview.ScheduledTime, zone) + " " + interview.InterviewLink
+	}
+	metadata := interviewMetadata(EventScheduled, interview)
+	if p.Role == RoleInterviewer {
+		return s.notifyInterviewer(ctx, p.ID, metadata, message)
+	}
+	hr, err := s.hrs.GetHR(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	return s.notify(ctx, hr.recipient(), message(hr.TimeZone), metadata)
+}
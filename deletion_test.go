@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeleteBlockedByUpcomingInterviews(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	tests := []struct {
+		name     string
+		start    time.Time
+		wantCode int
+	}{
+		{"ended", now.Add(-2 * time.Hour), http.StatusOK},
+		{"in progress", now.Add(-30 * time.Minute), http.StatusConflict},
+		{"upcoming", now.Add(24 * time.Hour), http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemoryStore()
+			seedPeople(t, ctx, store)
+			if _, err := store.CreateInterview(ctx, testInterview(tt.start)); err != nil {
+				t.Fatal(err)
+			}
+			s, _ := newTestServer(store)
+
+			w := httptest.NewRecorder()
+			s.routes().ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/interviewer/1?policy=block", nil))
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
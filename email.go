@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChannelEmail delivers notifications as HTML and plain-text emails over
+// SMTP. It is registered when smtp.host is set.
+const ChannelEmail = "email"
+
+// SMTPConfig holds the settings of the email channel.
+type SMTPConfig struct {
+	// Host is the SMTP server; the email channel is disabled while it is
+	// empty.
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// Username and Password authenticate with PLAIN auth, which Go only
+	// sends over TLS or to localhost. No auth is attempted without a
+	// username.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// From is the sender, e.g. "Interviews <interviews@example.com>".
+	From string `yaml:"from"`
+	// StartTLS upgrades the connection before authenticating and fails
+	// when the server does not offer it. Turn it off only for local SMTP
+	// sinks.
+	StartTLS bool `yaml:"starttls"`
+	// Timeout bounds each delivery, from dialing to QUIT.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func defaultSMTPConfig() SMTPConfig {
+	return SMTPConfig{
+		Port:     587,
+		StartTLS: true,
+		Timeout:  30 * time.Second,
+	}
+}
+
+// normalizeEmail checks that email is a bare address such as
+// "jane@example.com". An empty email is allowed.
+func normalizeEmail(email string) (string, error) {
+	if email == "" {
+		return "", nil
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" {
+		return "", fmt.Errorf("email %q is not a valid email address", email)
+	}
+	return addr.Address, nil
+}
+
+// emailSubjects maps notification events to email subjects.
+var emailSubjects = map[string]string{
+	EventScheduled:    "Interview scheduled",
+	EventUpdated:      "Interview updated",
+	EventCancelled:    "Interview cancelled",
+	EventPanelRemoved: "Removed from interview panel",
+}
+
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; line-height: 1.5">
+<h2>{{.Subject}}</h2>
+<p>{{.Message}}</p>
+{{- if .Link}}
+<p><a href="{{.Link}}">Join the interview</a></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// emailNotifier sends notifications as emails over SMTP.
+type emailNotifier struct {
+	config SMTPConfig
+}
+
+func (n emailNotifier) Address(r Recipient) string {
+	return r.Email
+}
+
+func (n emailNotifier) Send(ctx context.Context, notification Notification) error {
+	event := notification.Metadata["event"]
+	subject, ok := emailSubjects[event]
+	if !ok {
+		subject = "Interview notification"
+	}
+	link := ""
+	if calendarMethod(event) != CalendarCancel {
+		link = notification.Metadata["interview_link"]
+	}
+
+	var html bytes.Buffer
+	err := emailTemplate.Execute(&html, struct{ Subject, Message, Link string }{subject, notification.Message, link})
+	if err != nil {
+		return err
+	}
+
+	message, err := emailMessage{
+		From:     n.config.From,
+		To:       notification.Recipient,
+		Subject:  subject,
+		Text:     notification.Message,
+		HTML:     html.String(),
+		Calendar: notification.Calendar,
+		Method:   calendarMethod(event),
+	}.bytes()
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, notification.Recipient, message)
+}
+
+// send delivers message to the recipient over one SMTP session.
+func (n emailNotifier) send(ctx context.Context, to string, message []byte) error {
+	from, err := mail.ParseAddress(n.config.From)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.config.Timeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port)))
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if n.config.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		err = client.StartTLS(&tls.Config{ServerName: n.config.Host})
+		if err != nil {
+			return err
+		}
+	}
+	if n.config.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailMessage is an email with plain-text and HTML alternatives and, when
+// Calendar is set, an invite.ics attachment.
+type emailMessage struct {
+	From, To, Subject string
+	Text, HTML        string
+	// Calendar is an iCalendar object sent with Method.
+	Calendar, Method string
+}
+
+// bytes renders the message in RFC 5322 format, ready for SMTP DATA.
+func (m emailMessage) bytes() ([]byte, error) {
+	body, contentType, err := m.alternative()
+	if err != nil {
+		return nil, err
+	}
+	if m.Calendar != "" {
+		body, contentType, err = m.withCalendar(body, contentType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var message bytes.Buffer
+	for _, h := range [][2]string{
+		{"From", m.From},
+		{"To", m.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(m.From)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType},
+	} {
+		fmt.Fprintf(&message, "%s: %s\r\n", h[0], h[1])
+	}
+	message.WriteString("\r\n")
+	message.Write(body)
+	return message.Bytes(), nil
+}
+
+// alternative renders the multipart/alternative body holding the text and
+// HTML versions, and returns it with its content type.
+func (m emailMessage) alternative() ([]byte, string, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, "", err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "multipart/alternative; boundary=" + body.Boundary(), nil
+}
+
+// withCalendar wraps the alternative body in a multipart/mixed body that
+// also carries the calendar as invite.ics.
+func (m emailMessage) withCalendar(alternative []byte, contentType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	w, err := body.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := w.Write(alternative); err != nil {
+		return nil, "", err
+	}
+
+	w, err = body.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/calendar; charset=utf-8; method=" + m.Method + `; name="invite.ics"`},
+		"Content-Disposition":       {`attachment; filename="invite.ics"`},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(m.Calendar))
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return nil, "", err
+		}
+		encoded = encoded[76:]
+	}
+	if _, err := io.WriteString(w, encoded+"\r\n"); err != nil {
+		return nil, "", err
+	}
+
+	if err := body.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "multipart/mixed; boundary=" + body.Boundary(), nil
+}
+
+// messageID returns a unique Message-ID in the domain of from.
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
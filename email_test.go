@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSink accepts SMTP sessions on a local port and hands over the DATA of
+// each message. It offers no extensions, so no STARTTLS or AUTH.
+func smtpSink(t *testing.T) (port int, messages <-chan []byte) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, received)
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port, received
+}
+
+func serveSMTP(conn net.Conn, received chan<- []byte) {
+	defer conn.Close()
+	c := textproto.NewConn(conn)
+	c.PrintfLine("220 localhost ESMTP sink")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, _, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			c.PrintfLine("250 localhost")
+		case "DATA":
+			c.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(c.DotReader())
+			if err != nil {
+				return
+			}
+			received <- data
+			c.PrintfLine("250 OK")
+		case "QUIT":
+			c.PrintfLine("221 Bye")
+			return
+		default:
+			c.PrintfLine("250 OK")
+		}
+	}
+}
+
+func TestEmailNotifierSend(t *testing.T) {
+	port, messages := smtpSink(t)
+	notifier := emailNotifier{SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    port,
+		From:    "Interviews <interviews@example.com>",
+		Timeout: 5 * time.Second,
+	}}
+
+	calendar := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nMETHOD:REQUEST\r\nBEGIN:VEVENT\r\nUID:interview-1@example.com\r\nSUMMARY:Interview with Zoë\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	text := "You have an interview scheduled at Mon, 04 Mar 2030 15:30 IST with Zoë & team https://meet.example.com/abc"
+	err := notifier.Send(context.Background(), Notification{
+		Channel:   ChannelEmail,
+		Recipient: "ada@example.com",
+		Message:   text,
+		Metadata:  map[string]string{"event": EventScheduled, "interview_link": "https://meet.example.com/abc"},
+		Calendar:  calendar,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data []byte
+	select {
+	case data = <-messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sink received no message")
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := msg.Header.Get("To"); got != "ada@example.com" {
+		t.Errorf("To: got %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Interview scheduled" {
+		t.Errorf("Subject: got %q (%v), want %q", subject, err, "Interview scheduled")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type: got %q (%v), want multipart/mixed", mediaType, err)
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+
+	alternative, err := parts.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err = mime.ParseMediaType(alternative.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("first part: got %q (%v), want multipart/alternative", mediaType, err)
+	}
+	bodies := map[string]string{}
+	alternatives := multipart.NewReader(alternative, params["boundary"])
+	for {
+		part, err := alternatives.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// NextPart undoes the quoted-printable encoding.
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[mediaType] = string(body)
+	}
+	if bodies["text/plain"] != text {
+		t.Errorf("text part: got %q, want %q", bodies["text/plain"], text)
+	}
+	for _, want := range []string{"<h2>Interview scheduled</h2>", "Zoë &amp; team", `<a href="https://meet.example.com/abc">`} {
+		if !strings.Contains(bodies["text/html"], want) {
+			t.Errorf("HTML part %q does not contain %q", bodies["text/html"], want)
+		}
+	}
+
+	invite, err := parts.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err = mime.ParseMediaType(invite.Header.Get("Content-Type"))
+	if err != nil || mediaType != "text/calendar" || params["method"] != CalendarRequest {
+		t.Errorf("invite Content-Type: got %q %v (%v), want text/calendar with method %s", mediaType, params, err, CalendarRequest)
+	}
+	if invite.FileName() != "invite.ics" {
+		t.Errorf("invite file name: got %q, want invite.ics", invite.FileName())
+	}
+	encoded, err := io.ReadAll(invite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	if err != nil || string(decoded) != calendar {
+		t.Errorf("invite: got %q (%v), want %q", decoded, err, calendar)
+	}
+
+	if _, err := parts.NextPart(); err != io.EOF {
+		t.Errorf("got a part after the invite (%v)", err)
+	}
+}
+
+func TestEmailMessageSubject(t *testing.T) {
+	const subject = "Entretien planifié — Zoë"
+	data, err := emailMessage{From: "interviews@example.com", To: "ada@example.com", Subject: subject, Text: "hi", HTML: "<p>hi</p>"}.bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := msg.Header.Get("Subject")
+	if !strings.HasPrefix(header, "=?utf-8?q?") {
+		t.Errorf("Subject %q is not Q-encoded", header)
+	}
+	got, err := new(mime.WordDecoder).DecodeHeader(header)
+	if err != nil || got != subject {
+		t.Errorf("Subject: got %q (%v), want %q", got, err, subject)
+	}
+}
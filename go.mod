@@ -7,9 +7,13 @@ require (
 	github.com/go-resty/resty/v2 v2.7.0
 	github.com/go-sql-driver/mysql v1.7.1
 	github.com/gorilla/mux v1.8.0
+	github.com/mattn/go-sqlite3 v1.14.17
 	github.com/twilio/twilio-go v1.7.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
+require golang.org/x/crypto v0.7.0 // indirect
+
 require (
 	cloud.google.com/go v0.110.2 // indirect
 	cloud.google.com/go/compute v1.19.0 // indirect
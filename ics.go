@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar methods (RFC 5546). Invites are sent as requests; cancelling an
+// interview, or taking someone off its panel, sends a cancellation with the
+// same UID so calendars remove the event.
+const (
+	CalendarRequest = "REQUEST"
+	CalendarCancel  = "CANCEL"
+)
+
+// icsTimeFormat is the UTC DATE-TIME form of RFC 5545.
+const icsTimeFormat = "20060102T150405Z"
+
+// calendarMethod is the method of the invite sent with a notification of
+// event.
+func calendarMethod(event string) string {
+	if event == EventCancelled || event == EventPanelRemoved {
+		return CalendarCancel
+	}
+	return CalendarRequest
+}
+
+// calendarNotifier is implemented by channels that attach an iCalendar
+// invite to interview notifications. notify only builds the invite for
+// them.
+type calendarNotifier interface {
+	Notifier
+	attachesCalendar()
+}
+
+func (n emailNotifier) attachesCalendar() {}
+
+// calendarAttendee is one ATTENDEE, or the ORGANIZER, of an invite.
+type calendarAttendee struct {
+	Name        string
+	Email       string
+	PhoneNumber string
+	// Role is the ROLE parameter, e.g. REQ-PARTICIPANT.
+	Role string
+}
+
+// address is the calendar user address: their email, or their phone number
+// for people without one.
+func (a calendarAttendee) address() string {
+	if a.Email != "" {
+		return "mailto:" + a.Email
+	}
+	return "tel:" + a.PhoneNumber
+}
+
+// panelCalendarRoles maps panel roles to iCalendar attendee roles.
+var panelCalendarRoles = map[string]string{
+	PanelLead:      "REQ-PARTICIPANT",
+	PanelShadow:    "OPT-PARTICIPANT",
+	PanelNoteTaker: "NON-PARTICIPANT",
+}
+
+// interviewCalendar renders interview as an iCalendar object with one
+// VEVENT. The UID is the same for every version of the interview, and
+// Sequence orders the versions. The HR is the organizer and the candidate
+// and the panel are attendees; people who have since been deleted are left
+// out.
+func (s *Server) interviewCalendar(ctx context.Context, interview Interview, method string) (string, error) {
+	organizer, err := s.calendarOrganizer(ctx, interview)
+	if err != nil {
+		return "", err
+	}
+
+	var attendees []calendarAttendee
+	candidate, err := s.candidates.GetCandidate(ctx, interview.CandidateID)
+	if err == nil {
+		attendees = append(attendees, calendarAttendee{candidate.Name, candidate.Email, candidate.PhoneNumber, "REQ-PARTICIPANT"})
+	} else if !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	for _, p := range interview.Panel {
+		interviewer, err := s.interviewers.GetInterviewer(ctx, p.InterviewerID)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		attendees = append(attendees, calendarAttendee{interviewer.Name, interviewer.Email, interviewer.PhoneNumber, panelCalendarRoles[p.Role]})
+	}
+
+	status := "CONFIRMED"
+	if method == CalendarCancel {
+		status = "CANCELLED"
+	}
+	return s.writeCalendar(interview, method, status, calendarSummary(candidate), organizer, attendees), nil
+}
+
+// panelRemovalCalendar renders the cancellation sent to an interviewer taken
+// off interview's panel. It lists only them, so calendars drop the event
+// for them alone, and leaves the event itself confirmed.
+func (s *Server) panelRemovalCalendar(ctx context.Context, interview Interview, removed Panelist) (string, error) {
+	organizer, err := s.calendarOrganizer(ctx, interview)
+	if err != nil {
+		return "", err
+	}
+	candidate, err := s.candidates.GetCandidate(ctx, interview.CandidateID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	interviewer, err := s.interviewers.GetInterviewer(ctx, removed.InterviewerID)
+	if err != nil {
+		return "", err
+	}
+
+	attendee := calendarAttendee{interviewer.Name, interviewer.Email, interviewer.PhoneNumber, panelCalendarRoles[removed.Role]}
+	return s.writeCalendar(interview, CalendarCancel, "CONFIRMED", calendarSummary(candidate), organizer, []calendarAttendee{attendee}), nil
+}
+
+// calendarOrganizer returns the HR of interview as the organizer of its
+// invites, or nil when they have been deleted.
+func (s *Server) calendarOrganizer(ctx context.Context, interview Interview) (*calendarAttendee, error) {
+	hr, err := s.hrs.GetHR(ctx, interview.HRID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &calendarAttendee{Name: hr.Name, Email: hr.Email, PhoneNumber: hr.PhoneNumber}, nil
+}
+
+// calendarSummary is the title of the event of an interview of candidate,
+// who is the zero Candidate when they have been deleted.
+func calendarSummary(candidate Candidate) string {
+	if candidate.Name == "" {
+		return "Interview"
+	}
+	return "Interview with " + candidate.Name
+}
+
+// writeCalendar renders the VCALENDAR of interview.
+func (s *Server) writeCalendar(interview Interview, method, status, summary string, organizer *calendarAttendee, attendees []calendarAttendee) string {
+	var c icsWriter
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", "-//Interview System//Interview Scheduler//EN")
+	c.line("CALSCALE", "GREGORIAN")
+	c.line("METHOD", method)
+	c.line("BEGIN", "VEVENT")
+	c.line("UID", fmt.Sprintf("interview-%d@%s", interview.ID, s.calendarDomain()))
+	c.line("SEQUENCE", strconv.Itoa(interview.Sequence))
+	c.line("DTSTAMP", time.Now().UTC().Format(icsTimeFormat))
+	c.line("DTSTART", interview.ScheduledTime.UTC().Format(icsTimeFormat))
+	c.line("DTEND", interview.EndTime.UTC().Format(icsTimeFormat))
+	c.line("SUMMARY", icsText(summary))
+	if interview.InterviewLink != "" {
+		c.line("DESCRIPTION", icsText("Join the interview: "+interview.InterviewLink))
+		c.line("LOCATION", icsText(interview.InterviewLink))
+		c.line("URL", interview.InterviewLink)
+	}
+	c.line("STATUS", status)
+	if organizer != nil {
+		c.line("ORGANIZER;CN="+icsParam(organizer.Name), organizer.address())
+	}
+	for _, a := range attendees {
+		c.line("ATTENDEE;CN="+icsParam(a.Name)+";ROLE="+a.Role+";PARTSTAT=NEEDS-ACTION;RSVP=TRUE", a.address())
+	}
+	c.line("END", "VEVENT")
+	c.line("END", "VCALENDAR")
+	return c.String()
+}
+
+// calendarDomain is the domain interview UIDs are scoped to: the host of
+// booking.base_url.
+func (s *Server) calendarDomain() string {
+	u, err := url.Parse(s.booking.BaseURL)
+	if err != nil || u.Hostname() == "" {
+		return "interview-system"
+	}
+	return u.Hostname()
+}
+
+// notificationCalendar builds the invite attached to a notification with
+// metadata, or "" when the notification is not about one interview.
+func (s *Server) notificationCalendar(ctx context.Context, metadata map[string]string) (string, error) {
+	id, err := strconv.Atoi(metadata["interview_id"])
+	if err != nil {
+		return "", nil
+	}
+	interview, err := s.interviews.GetInterview(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if metadata["event"] == EventPanelRemoved {
+		interviewerID, err := strconv.Atoi(metadata["interviewer_id"])
+		if err != nil {
+			return "", fmt.Errorf("invalid interviewer_id %q in notification metadata", metadata["interviewer_id"])
+		}
+		return s.panelRemovalCalendar(ctx, interview, Panelist{interviewerID, metadata["panel_role"]})
+	}
+	return s.interviewCalendar(ctx, interview, calendarMethod(metadata["event"]))
+}
+
+// icsWriter builds an iCalendar object, folding content lines at 75 octets
+// as RFC 5545 requires.
+type icsWriter struct {
+	strings.Builder
+}
+
+func (w *icsWriter) line(name, value string) {
+	line := name + ":" + value
+	// Continuation lines start with a space, which counts towards the 75.
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// icsText escapes a TEXT property value.
+func icsText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// icsParam quotes a parameter value, such as a CN, which cannot contain
+// double quotes.
+func icsParam(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, "'") + `"`
+}
+
+func (s *Server) getInterviewCalendar(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	interview, err := s.interviews.GetInterview(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	method := CalendarRequest
+	if interview.Status == StatusCancelled {
+		method = CalendarCancel
+	}
+	calendar, err := s.interviewCalendar(r.Context(), interview, method)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8; method="+method)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="interview-%d.ics"`, id))
+	w.Write([]byte(calendar))
+}
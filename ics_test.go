@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPanelRemovalInvite(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	seedPeople(t, ctx, store)
+	removed := Interviewer{ID: 2, Name: "Grace", PhoneNumber: "+15550000002", Email: "grace@example.com", TimeZone: "UTC", NotificationChannels: []string{ChannelEmail}}
+	if err := store.UpdateInterviewer(ctx, removed); err != nil {
+		t.Fatal(err)
+	}
+	interview := testInterview(testTime)
+	interview.Panel = append(interview.Panel, Panelist{2, PanelShadow})
+	id, err := store.CreateInterview(ctx, interview)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, sent := newTestServer(store)
+	s.notifiers[ChannelEmail] = emailNotifier{}
+	w := httptest.NewRecorder()
+	s.routes().ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/interview/1/panelists/2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+
+	if len(*sent) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(*sent))
+	}
+	n := (*sent)[0]
+	if n.Recipient != removed.Email || n.Metadata["event"] != EventPanelRemoved {
+		t.Errorf("got a %s notification to %s, want %s to %s", n.Metadata["event"], n.Recipient, EventPanelRemoved, removed.Email)
+	}
+	if subject := emailSubjects[n.Metadata["event"]]; subject != "Removed from interview panel" {
+		t.Errorf("got subject %q", subject)
+	}
+
+	var attendees []string
+	unfolded := strings.ReplaceAll(n.Calendar, "\r\n ", "")
+	for _, line := range strings.Split(unfolded, "\r\n") {
+		if strings.HasPrefix(line, "ATTENDEE") {
+			attendees = append(attendees, line)
+		}
+	}
+	for _, want := range []string{"METHOD:CANCEL", "STATUS:CONFIRMED", "SEQUENCE:1"} {
+		if !strings.Contains(n.Calendar, want+"\r\n") {
+			t.Errorf("invite does not contain %s:\n%s", want, n.Calendar)
+		}
+	}
+	if len(attendees) != 1 || !strings.HasSuffix(attendees[0], "mailto:grace@example.com") || !strings.Contains(attendees[0], "ROLE=OPT-PARTICIPANT") {
+		t.Errorf("got attendees %q, want only the removed shadow", attendees)
+	}
+
+	stored, err := store.GetInterview(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.onPanel(2) || stored.Status != StatusScheduled {
+		t.Errorf("got interview %+v, want it scheduled without interviewer 2", stored)
+	}
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidLoop is returned for loop rounds that break the ordering rules.
+var ErrInvalidLoop = errors.New("invalid interview loop")
+
+// Loop groups the ordered rounds of interviews one candidate goes through,
+// such as a phone screen followed by technical and HR rounds.
+type Loop struct {
+	ID          int         `json:"id"`
+	CandidateID int         `json:"candidate_id"`
+	HRID        int         `json:"hr_id"`
+	Name        string      `json:"name"`
+	SameDay     bool        `json:"same_day"`
+	CreatedAt   time.Time   `json:"created_at"`
+	Rounds      []LoopRound `json:"rounds"`
+}
+
+// LoopRound is one round of a loop. Position counts from 1.
+type LoopRound struct {
+	Position    int    `json:"position"`
+	Name        string `json:"name"`
+	InterviewID int    `json:"interview_id"`
+}
+
+// RoundMissing is the status GET /loop/{id} reports for a round whose
+// interview no longer exists. Other rounds report their interview's status.
+const RoundMissing = "missing"
+
+// loopRoundView is a round together with its interview, as shown by
+// GET /loop/{id}.
+type loopRoundView struct {
+	LoopRound
+	Status    string     `json:"status"`
+	Interview *Interview `json:"interview,omitempty"`
+}
+
+// loopProgress summarises how far a candidate is through a loop.
+type loopProgress struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	// NextRound is the position of the first round still scheduled,
+	// confirmed or in progress, or 0.
+	NextRound int `json:"next_round"`
+}
+
+type loopView struct {
+	Loop
+	Rounds   []loopRoundView `json:"rounds"`
+	Progress loopProgress    `json:"progress"`
+}
+
+// createLoopRequest is the body of POST /loop. Every round is an interview
+// request for the loop's candidate and HR.
+type createLoopRequest struct {
+	CandidateID int                `json:"candidate_id"`
+	HRID        int                `json:"hr_id"`
+	Name        string             `json:"name"`
+	SameDay     bool               `json:"same_day"`
+	Rounds      []loopRoundRequest `json:"rounds"`
+}
+
+type loopRoundRequest struct {
+	Interview
+	Name string `json:"name"`
+}
+
+// roundConflicts are the conflicts of one round of a loop.
+type roundConflicts struct {
+	round     LoopRound
+	conflicts []Conflict
+}
+
+// checkRoundOrder enforces that every round starts once the previous one
+// has ended and the buffer has passed, and, for same-day loops, that all
+// rounds fall on one calendar day in loc.
+func (s *Server) checkRoundOrder(loop Loop, rounds []Interview, loc *time.Location) error {
+	for i := 1; i < len(rounds); i++ {
+		if rounds[i].ScheduledTime.Before(rounds[i-1].EndTime.Add(s.scheduling.Buffer)) {
+			return fmt.Errorf("%w: round %d must start at least %s after round %d ends", ErrInvalidLoop, loop.Rounds[i].Position, s.scheduling.Buffer, loop.Rounds[i-1].Position)
+		}
+	}
+	if loop.SameDay && len(rounds) > 0 {
+		first := rounds[0].ScheduledTime.In(loc)
+		last := rounds[len(rounds)-1].EndTime.Add(-time.Nanosecond).In(loc)
+		if first.YearDay() != last.YearDay() || first.Year() != last.Year() {
+			return fmt.Errorf("%w: a same-day loop must start and end on the same day in the candidate's time zone (%s)", ErrInvalidLoop, loc)
+		}
+	}
+	return nil
+}
+
+// checkLoopReschedule runs checkRoundOrder on the loop holding existing, if
+// any, as if existing were moved to the times of moved. Rounds whose
+// interview is gone or cancelled no longer take part in the ordering.
+func (s *Server) checkLoopReschedule(ctx context.Context, tx Store, existing, moved Interview) error {
+	loops, err := tx.ListLoops(ctx, existing.CandidateID)
+	if err != nil {
+		return err
+	}
+	for _, loop := range loops {
+		held := false
+		for _, round := range loop.Rounds {
+			held = held || round.InterviewID == existing.ID
+		}
+		if !held {
+			continue
+		}
+
+		var kept []LoopRound
+		var rounds []Interview
+		for _, round := range loop.Rounds {
+			interview := moved
+			if round.InterviewID != existing.ID {
+				interview, err = tx.GetInterview(ctx, round.InterviewID)
+				if errors.Is(err, ErrNotFound) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if interview.Status == StatusCancelled {
+					continue
+				}
+			}
+			kept = append(kept, round)
+			rounds = append(rounds, interview)
+		}
+		loop.Rounds = kept
+
+		loc, err := participantLocation(ctx, tx, Participant{RoleCandidate, loop.CandidateID})
+		if err != nil {
+			return err
+		}
+		return s.checkRoundOrder(loop, rounds, loc)
+	}
+	return nil
+}
+
+// scheduleLoop books every round of the loop and saves the loop, all in one
+// atomic unit. Nothing is booked when any round has conflicts; they are
+// returned grouped by round.
+func (s *Server) scheduleLoop(ctx context.Context, loop *Loop, rounds []Interview) ([]roundConflicts, error) {
+	var participants []Participant
+	for _, round := range rounds {
+		participants = append(participants, round.participants()...)
+	}
+
+	var conflicts []roundConflicts
+	err := s.tx.Atomically(ctx, participants, func(tx Store) error {
+		for i, round := range rounds {
+			c, err := s.checkSlot(ctx, tx, round.participants(), round, 0)
+			if err != nil {
+				return err
+			}
+			if len(c) > 0 {
+				conflicts = append(conflicts, roundConflicts{loop.Rounds[i], c})
+			}
+		}
+		if len(conflicts) > 0 {
+			return nil
+		}
+
+		for i := range rounds {
+			rounds[i].Status = StatusScheduled
+			id, err := tx.CreateInterview(ctx, rounds[i])
+			if err != nil {
+				return err
+			}
+			rounds[i].ID = id
+			loop.Rounds[i].InterviewID = id
+		}
+		var err error
+		loop.ID, err = tx.CreateLoop(ctx, *loop)
+		return err
+	})
+	return conflicts, err
+}
+
+// viewLoop looks up the interviews of the loop's rounds and works out its
+// progress.
+func (s *Server) viewLoop(ctx context.Context, loop Loop) (loopView, error) {
+	view := loopView{Loop: loop, Rounds: []loopRoundView{}}
+	view.Progress.Total = len(loop.Rounds)
+	for _, round := range loop.Rounds {
+		rv := loopRoundView{LoopRound: round, Status: RoundMissing}
+		interview, err := s.interviews.GetInterview(ctx, round.InterviewID)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return view, err
+		default:
+			rv.Status, rv.Interview = interview.Status, &interview
+		}
+
+		if rv.Status == StatusCompleted {
+			view.Progress.Completed++
+		}
+		if view.Progress.NextRound == 0 && rv.Interview != nil && (interview.open() || interview.Status == StatusInProgress) {
+			view.Progress.NextRound = round.Position
+		}
+		view.Rounds = append(view.Rounds, rv)
+	}
+	return view, nil
+}
+
+func (s *Server) createLoop(w http.ResponseWriter, r *http.Request) {
+	var req createLoopRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.CandidateID == 0 || req.HRID == 0 {
+		http.Error(w, "Candidate or HR ID is missing or zero", http.StatusBadRequest)
+		return
+	}
+	if len(req.Rounds) == 0 {
+		http.Error(w, "A loop needs at least one round", http.StatusBadRequest)
+		return
+	}
+
+	candidate, err := s.candidates.GetCandidate(r.Context(), req.CandidateID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, fmt.Sprintf("Candidate %d does not exist", req.CandidateID), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	hr, err := s.hrs.GetHR(r.Context(), req.HRID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, fmt.Sprintf("HR %d does not exist", req.HRID), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	loop := Loop{
+		CandidateID: req.CandidateID,
+		HRID:        req.HRID,
+		Name:        req.Name,
+		SameDay:     req.SameDay,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	rounds := make([]Interview, len(req.Rounds))
+	for i, round := range req.Rounds {
+		interview := round.Interview
+		interview.ID = 0
+		interview.CandidateID, interview.HRID = req.CandidateID, req.HRID
+		if err := interview.normalizeTimes(s.scheduling.DefaultDuration); err != nil {
+			http.Error(w, fmt.Sprintf("round %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+		if err := interview.normalizePanel(); err != nil {
+			http.Error(w, fmt.Sprintf("round %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+		rounds[i] = interview
+		loop.Rounds = append(loop.Rounds, LoopRound{Position: i + 1, Name: round.Name})
+	}
+
+	err = s.checkRoundOrder(loop, rounds, location(candidate.TimeZone))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conflicts, err := s.scheduleLoop(r.Context(), &loop, rounds)
+	if err != nil {
+		if errors.Is(err, ErrUnknownParticipant) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if len(conflicts) > 0 {
+		var messages []string
+		var all []Conflict
+		for _, rc := range conflicts {
+			for _, c := range rc.conflicts {
+				messages = append(messages, fmt.Sprintf("Round %d (%s): %s", rc.round.Position, rc.round.Name, c))
+			}
+			all = append(all, rc.conflicts...)
+		}
+		sendConflictsMessage(w, strings.Join(messages, "; "), all)
+		return
+	}
+
+	for _, round := range rounds {
+		err = s.notifyPanel(r.Context(), round, EventScheduled, "You have an interview scheduled at ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	metadata := map[string]string{"event": EventScheduled, "loop_id": strconv.Itoa(loop.ID)}
+	err = s.notify(r.Context(), hr.recipient(), loopSchedule(loop, rounds, hr.TimeZone, nil), metadata)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = s.notify(r.Context(), candidate.recipient(), loopSchedule(loop, rounds, candidate.TimeZone, s.manageNote), metadata)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	SendResponse(map[string]any{"message": "Interview Loop Created Successfully", "id": loop.ID}, w)
+}
+
+// loopSchedule is the notification listing every round of a loop, with
+// times in zone. When note is not nil its result for each round is
+// appended to the round's line.
+func loopSchedule(loop Loop, rounds []Interview, zone string, note func(Interview) string) string {
+	lines := []string{"Your interview loop " + loop.Name + " is scheduled:"}
+	for i, round := range rounds {
+		line := fmt.Sprintf("%d. %s at %s %s", i+1, loop.Rounds[i].Name, localTime(round.ScheduledTime, zone), round.InterviewLink)
+		if note != nil {
+			line += note(round)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Server) getLoop(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	loop, err := s.loops.GetLoop(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	view, err := s.viewLoop(r.Context(), loop)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(view)
+}
+
+func (s *Server) getCandidateLoops(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	loops, err := s.loops.ListLoops(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	views := []loopView{}
+	for _, loop := range loops {
+		view, err := s.viewLoop(r.Context(), loop)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		views = append(views, view)
+	}
+	json.NewEncoder(w).Encode(views)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRescheduleLoopRound(t *testing.T) {
+	const loop = `{"candidate_id": 1, "hr_id": 1, "name": "Onsite", "same_day": true, "rounds": [
+		{"name": "Coding", "interviewer_id": 1, "scheduled_time": "2030-03-04T10:00:00Z", "duration_minutes": 60},
+		{"name": "Design", "interviewer_id": 2, "scheduled_time": "2030-03-04T12:00:00Z", "duration_minutes": 60}]}`
+	tests := []struct {
+		name        string
+		round       int
+		to          string
+		cancelFirst bool
+		viaLink     bool
+		wantCode    int
+	}{
+		{name: "after the previous round", round: 2, to: "2030-03-04T14:00:00Z", wantCode: http.StatusOK},
+		{name: "before the previous round", round: 2, to: "2030-03-04T09:00:00Z", wantCode: http.StatusConflict},
+		{name: "into the next round", round: 1, to: "2030-03-04T11:30:00Z", wantCode: http.StatusConflict},
+		{name: "onto another day", round: 2, to: "2030-03-05T12:00:00Z", wantCode: http.StatusConflict},
+		{name: "previous round cancelled", round: 2, to: "2030-03-04T09:00:00Z", cancelFirst: true, wantCode: http.StatusOK},
+		{name: "through the manage link", round: 2, to: "2030-03-04T09:00:00Z", viaLink: true, wantCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemoryStore()
+			seedPeople(t, ctx, store)
+			s, _ := newTestServer(store)
+			s.booking.Secret = "secret"
+			router := s.routes()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/loop", strings.NewReader(loop)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("creating the loop: got status %d: %s", w.Code, w.Body)
+			}
+			if tt.cancelFirst {
+				err := store.SetInterviewStatus(ctx, StatusChange{InterviewID: 1, FromStatus: StatusScheduled, ToStatus: StatusCancelled, CreatedAt: testTime})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			before, err := store.GetInterview(ctx, tt.round)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var req *http.Request
+			if tt.viaLink {
+				token, err := signToken("manage", ManageLink{before.ID, before.CandidateID}, s.booking.Secret)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body := fmt.Sprintf(`{"scheduled_time": %q}`, tt.to)
+				req = httptest.NewRequest(http.MethodPost, "/manage/"+token+"/reschedule", strings.NewReader(body))
+			} else {
+				body := fmt.Sprintf(`{"interviewer_id": %d, "candidate_id": 1, "hr_id": 1, "scheduled_time": %q, "duration_minutes": 60}`, before.InterviewerID, tt.to)
+				req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/interview/%d", tt.round), strings.NewReader(body))
+			}
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+
+			after, err := store.GetInterview(ctx, tt.round)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := before.ScheduledTime
+			if tt.wantCode == http.StatusOK {
+				want, _ = time.Parse(time.RFC3339, tt.to)
+			}
+			if !after.ScheduledTime.Equal(want) {
+				t.Errorf("round %d is at %s, want %s", tt.round, after.ScheduledTime, want)
+			}
+		})
+	}
+}
+
+func TestPurgeLoopRound(t *testing.T) {
+	const loop = `{"candidate_id": 1, "hr_id": 1, "name": "Onsite", "rounds": [
+		{"name": "Coding", "interviewer_id": 1, "scheduled_time": "2030-03-04T10:00:00Z", "duration_minutes": 60},
+		{"name": "Design", "interviewer_id": 2, "scheduled_time": "2030-03-04T12:00:00Z", "duration_minutes": 60}]}`
+
+	for _, backend := range storeBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.open(t)
+			seedPeople(t, ctx, store)
+			s, _ := newTestServer(store)
+			s.admin.Token = "token"
+			router := s.routes()
+			purge := func(id int) {
+				t.Helper()
+				r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/admin/interview/%d", id), nil)
+				r.Header.Set("Authorization", "Bearer token")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+				if w.Code != http.StatusOK {
+					t.Fatalf("purging interview %d: got status %d: %s", id, w.Code, w.Body)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/loop", strings.NewReader(loop)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("creating the loop: got status %d: %s", w.Code, w.Body)
+			}
+			created, err := store.ListLoops(ctx, 1)
+			if err != nil || len(created) != 1 || len(created[0].Rounds) != 2 {
+				t.Fatalf("got loops %+v (%v), want one with two rounds", created, err)
+			}
+			first, second := created[0].Rounds[0], created[0].Rounds[1]
+
+			// The other round stays at its position.
+			purge(first.InterviewID)
+			got, err := store.GetLoop(ctx, created[0].ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Rounds) != 1 || got.Rounds[0] != second {
+				t.Errorf("got rounds %+v, want only %+v", got.Rounds, second)
+			}
+
+			// A loop without rounds is removed.
+			purge(second.InterviewID)
+			if _, err := store.GetLoop(ctx, created[0].ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("getting the emptied loop: got %v, want ErrNotFound", err)
+			}
+
+			// Interview ids freed by the purge can be put in a new loop.
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/loop", strings.NewReader(loop)))
+			if w.Code != http.StatusOK {
+				t.Errorf("creating another loop: got status %d: %s", w.Code, w.Body)
+			}
+		})
+	}
+}
@@ -3,124 +3,127 @@ package main
 import (
 	"context"
 	"crypto/rand"
-	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/go-resty/resty/v2"
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/gorilla/mux"
 	"github.com/twilio/twilio-go"
 	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
 )
 
 type PubSubConfig struct {
-	ProjectID      string
-	TopicID        string
-	SubscriptionID string
+	ProjectID       string `yaml:"project_id"`
+	TopicID         string `yaml:"topic_id"`
+	SubscriptionID  string `yaml:"subscription_id"`
+	CredentialsFile string `yaml:"credentials_file"`
 }
 
 type TwilioConfig struct {
-	AccountSID  string
-	AuthToken   string
-	PhoneNumber string
+	AccountSID  string `yaml:"account_sid"`
+	AuthToken   string `yaml:"auth_token"`
+	PhoneNumber string `yaml:"phone_number"`
 }
 
 type Interviewer struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	PhoneNumber string `json:"phone_number"`
+	Email       string `json:"email"`
+	// TimeZone is an IANA zone name; notifications are shown in it.
+	TimeZone string `json:"time_zone"`
+	// NotificationChannels lists the channels notifications are sent on;
+	// SMS when empty.
+	NotificationChannels []string `json:"notification_channels"`
 }
 
 type Candidate struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	PhoneNumber string `json:"phone_number"`
+	Email       string `json:"email"`
+	// TimeZone is an IANA zone name; notifications are shown in it.
+	TimeZone string `json:"time_zone"`
+	// NotificationChannels lists the channels notifications are sent on;
+	// SMS when empty.
+	NotificationChannels []string `json:"notification_channels"`
 }
 
 type HR struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	PhoneNumber string `json:"phone_number"`
+	Email       string `json:"email"`
+	// TimeZone is an IANA zone name; notifications are shown in it.
+	TimeZone string `json:"time_zone"`
+	// NotificationChannels lists the channels notifications are sent on;
+	// SMS when empty.
+	NotificationChannels []string `json:"notification_channels"`
 }
 
 type Interview struct {
-	ID            int    `json:"id"`
-	InterviewerID int    `json:"interviewer_id"`
-	CandidateID   int    `json:"candidate_id"`
-	HRID          int    `json:"hr_id"`
-	ScheduledTime string `json:"scheduled_time"`
-	Rescheduled   bool   `json:"rescheduled"`
-	InterviewLink string `json:"interview_link"`
+	ID              int       `json:"id"`
+	InterviewerID   int       `json:"interviewer_id"`
+	CandidateID     int       `json:"candidate_id"`
+	HRID            int       `json:"hr_id"`
+	ScheduledTime   time.Time `json:"scheduled_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	// EndTime is derived from ScheduledTime and DurationMinutes; requests
+	// may send it instead of a duration.
+	EndTime       time.Time `json:"end_time"`
+	Rescheduled   bool      `json:"rescheduled"`
+	InterviewLink string    `json:"interview_link"`
+	// Panel lists every interviewer, the lead (InterviewerID) first.
+	Panel []Panelist `json:"panel"`
+	// Status is changed only through the status endpoints; see
+	// statusTransitions.
+	Status string `json:"status"`
+	// Sequence versions the interview's calendar event. The store bumps it
+	// on every update and on cancellation.
+	Sequence int `json:"sequence"`
 }
 
-var db *sql.DB
-
 var pubsubConfig PubSubConfig
-var twilioConfig TwilioConfig
 
 func main() {
 
-	dbConnString := "root:Sanju2001@/interview_system?charset=utf8&parseTime=True&loc=Local"
-	var err error
-	db, err = sql.Open("mysql", dbConnString)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
+	cfg, err := loadConfig(os.Args[1:])
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer db.Close()
-	fmt.Println("Connected to the Database Successfully!")
-
-	pubsubConfig = PubSubConfig{
-		ProjectID:      "interviewsystem",
-		TopicID:        "notification_topic",
-		SubscriptionID: "notification_subscription",
-	}
+	pubsubConfig = cfg.PubSub
+	notifiers := newNotifiers(cfg)
 
-	twilioConfig = TwilioConfig{
-		AccountSID:  "ACe2408ed74c8ebd1cbf6fc3f7dff60f20",
-		AuthToken:   "32727baaed87150f0ff8423e7acdaf76",
-		PhoneNumber: "+13156311532",
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		log.Fatal(err)
 	}
+	defer closeStore()
+	fmt.Printf("Using the %s store\n", cfg.Store)
 
-	go startPubSubSubscriber()
-
-	router := mux.NewRouter()
-
-	router.HandleFunc("/interviewer/{id}", getInterviewer).Methods("GET")
-	router.HandleFunc("/interviewer", createInterviewer).Methods("POST")
-	router.HandleFunc("/interviewer/{id}", updateInterviewer).Methods("PUT")
-	router.HandleFunc("/interviewer/{id}", deleteInterviewer).Methods("DELETE")
-	router.HandleFunc("/interviewers", GetAllInterviewers).Methods("GET")
+	go startPubSubSubscriber(notifiers)
 
-	router.HandleFunc("/candidate", createCandidate).Methods("POST")
-	router.HandleFunc("/candidates", getAllCandidates).Methods("GET")
-	router.HandleFunc("/candidate/{id}", getCandidate).Methods("GET")
-	router.HandleFunc("/candidate/{id}", updateCandidate).Methods("PUT")
-	router.HandleFunc("/candidate/{id}", deleteCandidate).Methods("DELETE")
+	server := NewServer(store, cfg.Scheduling, cfg.Booking, cfg.Admin, notifiers)
 
-	router.HandleFunc("/hr/{id}", getHR).Methods("GET")
-	router.HandleFunc("/hr", createHR).Methods("POST")
-	router.HandleFunc("/hr/{id}", updateHR).Methods("PUT")
-	router.HandleFunc("/hr/{id}", deleteHR).Methods("DELETE")
-	router.HandleFunc("/hrs", GetAllHRs).Methods("GET")
-
-	router.HandleFunc("/interview/{id}", getInterview).Methods("GET")
-	router.HandleFunc("/interview", createInterview).Methods("POST")
-	router.HandleFunc("/interview/{id}", UpdateInterview).Methods("PUT")
-	router.HandleFunc("/interview/{id}", DeleteInterview).Methods("DELETE")
-	router.HandleFunc("/interviews", GetAllInterviews).Methods("GET")
-
-	log.Fatal(http.ListenAndServe(":8000", router))
+	log.Fatal(http.ListenAndServe(cfg.ListenAddr, server.routes()))
 }
 
-func createInterviewer(w http.ResponseWriter, r *http.Request) {
+func (s *Server) createInterviewer(w http.ResponseWriter, r *http.Request) {
 	var interviewer Interviewer
 	err := json.NewDecoder(r.Body).Decode(&interviewer)
 	if err != nil {
@@ -128,13 +131,13 @@ func createInterviewer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	existinginterviewer, err := getInterviewerByPhoneNumber(interviewer.PhoneNumber)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	_, err = s.interviewers.GetInterviewerByPhoneNumber(r.Context(), interviewer.PhoneNumber)
+	if err == nil {
+		http.Error(w, "Phone number is already registered for another hr", http.StatusBadRequest)
 		return
 	}
-	if existinginterviewer != nil {
-		http.Error(w, "Phone number is already registered for another hr", http.StatusBadRequest)
+	if !errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -143,14 +146,25 @@ func createInterviewer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stmt, err := db.Prepare("INSERT INTO interviewer (id, name, phone_number) VALUES (?, ?, ?)")
+	interviewer.TimeZone, err = normalizeTimeZone(interviewer.TimeZone)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interviewer.Email, err = normalizeEmail(interviewer.Email)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interviewer.NotificationChannels, err = s.notifiers.normalizeChannels(interviewer.recipient())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(interviewer.ID, interviewer.Name, interviewer.PhoneNumber)
+	err = s.interviewers.CreateInterviewer(r.Context(), interviewer)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -160,16 +174,15 @@ func createInterviewer(w http.ResponseWriter, r *http.Request) {
 	// w.WriteHeader(http.StatusCreated)
 }
 
-func getInterviewer(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id := params["id"]
-
-	var interviewer Interviewer
+func (s *Server) getInterviewer(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
 
-	row := db.QueryRow("SELECT * FROM interviewer WHERE id = ?", id)
-	err := row.Scan(&interviewer.ID, &interviewer.Name, &interviewer.PhoneNumber)
+	interviewer, err := s.interviewers.GetInterviewer(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -180,7 +193,7 @@ func getInterviewer(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(interviewer)
 }
 
-func createCandidate(w http.ResponseWriter, r *http.Request) {
+func (s *Server) createCandidate(w http.ResponseWriter, r *http.Request) {
 	var candidate Candidate
 	err := json.NewDecoder(r.Body).Decode(&candidate)
 	if err != nil {
@@ -188,13 +201,13 @@ func createCandidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	existingCandidate, err := getCandidateByPhoneNumber(candidate.PhoneNumber)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	_, err = s.candidates.GetCandidateByPhoneNumber(r.Context(), candidate.PhoneNumber)
+	if err == nil {
+		http.Error(w, "Phone number is already registered for another candidate", http.StatusBadRequest)
 		return
 	}
-	if existingCandidate != nil {
-		http.Error(w, "Phone number is already registered for another candidate", http.StatusBadRequest)
+	if !errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -203,14 +216,25 @@ func createCandidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stmt, err := db.Prepare("INSERT INTO candidate (id, name, phone_number) VALUES (?, ?, ?)")
+	candidate.TimeZone, err = normalizeTimeZone(candidate.TimeZone)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(candidate.ID, candidate.Name, candidate.PhoneNumber)
+	candidate.Email, err = normalizeEmail(candidate.Email)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	candidate.NotificationChannels, err = s.notifiers.normalizeChannels(candidate.recipient())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.candidates.CreateCandidate(r.Context(), candidate)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -220,16 +244,15 @@ func createCandidate(w http.ResponseWriter, r *http.Request) {
 	// w.WriteHeader(http.StatusCreated)
 }
 
-func getCandidate(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id := params["id"]
-
-	var candidate Candidate
+func (s *Server) getCandidate(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
 
-	row := db.QueryRow("SELECT * FROM candidate WHERE id = ?", id)
-	err := row.Scan(&candidate.ID, &candidate.Name, &candidate.PhoneNumber)
+	candidate, err := s.candidates.GetCandidate(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -240,7 +263,7 @@ func getCandidate(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(candidate)
 }
 
-func createHR(w http.ResponseWriter, r *http.Request) {
+func (s *Server) createHR(w http.ResponseWriter, r *http.Request) {
 	var hr HR
 	err := json.NewDecoder(r.Body).Decode(&hr)
 	if err != nil {
@@ -248,13 +271,13 @@ func createHR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	existinghr, err := getHRByPhoneNumber(hr.PhoneNumber)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	_, err = s.hrs.GetHRByPhoneNumber(r.Context(), hr.PhoneNumber)
+	if err == nil {
+		http.Error(w, "Phone number is already registered for another hr", http.StatusBadRequest)
 		return
 	}
-	if existinghr != nil {
-		http.Error(w, "Phone number is already registered for another hr", http.StatusBadRequest)
+	if !errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -263,14 +286,25 @@ func createHR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stmt, err := db.Prepare("INSERT INTO hr (id, name, phone_number) VALUES (?, ?, ?)")
+	hr.TimeZone, err = normalizeTimeZone(hr.TimeZone)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hr.Email, err = normalizeEmail(hr.Email)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hr.NotificationChannels, err = s.notifiers.normalizeChannels(hr.recipient())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(hr.ID, hr.Name, hr.PhoneNumber)
+	err = s.hrs.CreateHR(r.Context(), hr)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -279,16 +313,15 @@ func createHR(w http.ResponseWriter, r *http.Request) {
 	// w.WriteHeader(http.StatusCreated)
 }
 
-func getHR(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id := params["id"]
-
-	var hr HR
+func (s *Server) getHR(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
 
-	row := db.QueryRow("SELECT * FROM hr WHERE id = ?", id)
-	err := row.Scan(&hr.ID, &hr.Name, &hr.PhoneNumber)
+	hr, err := s.hrs.GetHR(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -299,16 +332,15 @@ func getHR(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(hr)
 }
 
-func getInterview(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id := params["id"]
-
-	var interview Interview
+func (s *Server) getInterview(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
 
-	row := db.QueryRow("SELECT * FROM interview WHERE id = ?", id)
-	err := row.Scan(&interview.ID, &interview.InterviewerID, &interview.CandidateID, &interview.HRID, &interview.ScheduledTime, &interview.Rescheduled, &interview.InterviewLink)
+	interview, err := s.interviews.GetInterview(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			http.NotFound(w, r)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -319,73 +351,33 @@ func getInterview(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(interview)
 }
 
-func getCandidateByPhoneNumber(phoneNumber string) (*Candidate, error) {
-	query := "SELECT id, name, phone_number FROM candidate WHERE phone_number = ? LIMIT 1"
-
-	row := db.QueryRow(query, phoneNumber)
-
-	candidate := &Candidate{}
-	err := row.Scan(&candidate.ID, &candidate.Name, &candidate.PhoneNumber)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // Candidate not found
-		}
-		return nil, err
-	}
-
-	return candidate, nil
-}
-
-func getInterviewerByPhoneNumber(phoneNumber string) (*Interviewer, error) {
-	query := "SELECT id, name, phone_number FROM interviewer WHERE phone_number = ? LIMIT 1"
-
-	row := db.QueryRow(query, phoneNumber)
-
-	interviewer := &Interviewer{}
-	err := row.Scan(&interviewer.ID, &interviewer.Name, &interviewer.PhoneNumber)
+func (s *Server) updateCandidate(w http.ResponseWriter, r *http.Request) {
+	var candidate Candidate
+	err := json.NewDecoder(r.Body).Decode(&candidate)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // Interviewer not found
-		}
-		return nil, err
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	return interviewer, nil
-}
-
-func getHRByPhoneNumber(phoneNumber string) (*HR, error) {
-	query := "SELECT id, name, phone_number FROM hr WHERE phone_number = ? LIMIT 1"
-
-	row := db.QueryRow(query, phoneNumber)
-
-	hr := &HR{}
-	err := row.Scan(&hr.ID, &hr.Name, &hr.PhoneNumber)
+	candidate.TimeZone, err = normalizeTimeZone(candidate.TimeZone)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // HR not found
-		}
-		return nil, err
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	return hr, nil
-}
-
-func updateCandidate(w http.ResponseWriter, r *http.Request) {
-	var candidate Candidate
-	err := json.NewDecoder(r.Body).Decode(&candidate)
+	candidate.Email, err = normalizeEmail(candidate.Email)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	stmt, err := db.Prepare("UPDATE candidate SET name = ?, phone_number = ? WHERE id = ?")
+	candidate.NotificationChannels, err = s.notifiers.normalizeChannels(candidate.recipient())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(candidate.Name, candidate.PhoneNumber, candidate.ID)
+	err = s.candidates.UpdateCandidate(r.Context(), candidate)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -394,71 +386,47 @@ func updateCandidate(w http.ResponseWriter, r *http.Request) {
 	// w.WriteHeader(http.StatusOK)
 }
 
-func deleteCandidate(w http.ResponseWriter, r *http.Request) {
-	candidateID := mux.Vars(r)["id"]
-
-	stmt, err := db.Prepare("DELETE FROM candidate WHERE id = ?")
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer stmt.Close()
+func (s *Server) deleteCandidate(w http.ResponseWriter, r *http.Request) {
+	s.deletePerson(w, r, RoleCandidate)
+}
 
-	_, err = stmt.Exec(candidateID)
+func (s *Server) getAllCandidates(w http.ResponseWriter, r *http.Request) {
+	candidates, err := s.candidates.ListCandidates(r.Context())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	SendResponse(map[string]string{"message": "Candidate was deleted Successfully"}, w)
-	// w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(candidates)
 }
 
-func getAllCandidates(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT * FROM candidate")
+func (s *Server) updateHR(w http.ResponseWriter, r *http.Request) {
+	var hr HR
+	err := json.NewDecoder(r.Body).Decode(&hr)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer rows.Close()
 
-	var candidates []Candidate
-
-	for rows.Next() {
-		var candidate Candidate
-		err := rows.Scan(&candidate.ID, &candidate.Name, &candidate.PhoneNumber)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		candidates = append(candidates, candidate)
-	}
-
-	err = rows.Err()
+	hr.TimeZone, err = normalizeTimeZone(hr.TimeZone)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	json.NewEncoder(w).Encode(candidates)
-}
-
-func updateHR(w http.ResponseWriter, r *http.Request) {
-	var hr HR
-	err := json.NewDecoder(r.Body).Decode(&hr)
+	hr.Email, err = normalizeEmail(hr.Email)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	stmt, err := db.Prepare("UPDATE hr SET name = ?, phone_number = ? WHERE id = ?")
+	hr.NotificationChannels, err = s.notifiers.normalizeChannels(hr.recipient())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(hr.Name, hr.PhoneNumber, hr.ID)
+	err = s.hrs.UpdateHR(r.Context(), hr)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -467,41 +435,37 @@ func updateHR(w http.ResponseWriter, r *http.Request) {
 	// w.WriteHeader(http.StatusOK)
 }
 
-func deleteHR(w http.ResponseWriter, r *http.Request) {
-	hrID := mux.Vars(r)["id"]
+func (s *Server) deleteHR(w http.ResponseWriter, r *http.Request) {
+	s.deletePerson(w, r, RoleHR)
+}
 
-	stmt, err := db.Prepare("DELETE FROM hr WHERE id = ?")
+func (s *Server) updateInterviewer(w http.ResponseWriter, r *http.Request) {
+	var interviewer Interviewer
+	err := json.NewDecoder(r.Body).Decode(&interviewer)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(hrID)
+	interviewer.TimeZone, err = normalizeTimeZone(interviewer.TimeZone)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	SendResponse(map[string]string{"message": "HR was deleted Successfully"}, w)
-	// w.WriteHeader(http.StatusOK)
-}
 
-func updateInterviewer(w http.ResponseWriter, r *http.Request) {
-	var interviewer Interviewer
-	err := json.NewDecoder(r.Body).Decode(&interviewer)
+	interviewer.Email, err = normalizeEmail(interviewer.Email)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	stmt, err := db.Prepare("UPDATE interviewer SET name = ?, phone_number = ? WHERE id = ?")
+	interviewer.NotificationChannels, err = s.notifiers.normalizeChannels(interviewer.recipient())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(interviewer.Name, interviewer.PhoneNumber, interviewer.ID)
+	err = s.interviewers.UpdateInterviewer(r.Context(), interviewer)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -512,162 +476,120 @@ func updateInterviewer(w http.ResponseWriter, r *http.Request) {
 	// w.WriteHeader(http.StatusOK)
 }
 
-func deleteInterviewer(w http.ResponseWriter, r *http.Request) {
-	interviewerID := mux.Vars(r)["id"]
+func (s *Server) deleteInterviewer(w http.ResponseWriter, r *http.Request) {
+	s.deletePerson(w, r, RoleInterviewer)
+}
 
-	stmt, err := db.Prepare("DELETE FROM interviewer WHERE id = ?")
+func (s *Server) GetAllInterviewers(w http.ResponseWriter, r *http.Request) {
+	interviewers, err := s.interviewers.ListInterviewers(r.Context())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(interviewerID)
+	json.NewEncoder(w).Encode(interviewers)
+}
+
+func (s *Server) GetAllHRs(w http.ResponseWriter, r *http.Request) {
+	hrs, err := s.hrs.ListHRs(r.Context())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	SendResponse(map[string]string{"message": "Interviewer was deleted Successfully"}, w)
-	// w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(hrs)
 }
 
-func GetAllInterviewers(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT * FROM interviewer")
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func (s *Server) UpdateInterview(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
 		return
 	}
-	defer rows.Close()
 
-	var interviewers []Interviewer
-
-	for rows.Next() {
-		var interviewer Interviewer
-		err := rows.Scan(&interviewer.ID, &interviewer.Name, &interviewer.PhoneNumber)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		interviewers = append(interviewers, interviewer)
-	}
+	var req updateInterviewRequest
 
-	err = rows.Err()
+	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	interview := req.Interview
 
-	json.NewEncoder(w).Encode(interviewers)
-}
-
-func GetAllHRs(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT * FROM hr")
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if (interview.InterviewerID == 0 && len(interview.Panel) == 0) || interview.CandidateID == 0 || interview.HRID == 0 {
+		http.Error(w, "Interviewer, candidate, or HR ID is missing or zero", http.StatusBadRequest)
 		return
 	}
-	defer rows.Close()
 
-	var hrs []HR
-
-	for rows.Next() {
-		var hr HR
-		err := rows.Scan(&hr.ID, &hr.Name, &hr.PhoneNumber)
+	if interview.Panel == nil {
+		// Leaving out the panel keeps the current one under the given lead.
+		existing, err := s.interviews.GetInterview(r.Context(), id)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			if errors.Is(err, ErrNotFound) {
+				http.NotFound(w, r)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
 			return
 		}
-		hrs = append(hrs, hr)
-	}
-
-	err = rows.Err()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		interview.Panel = existing.panelWithLead(interview.InterviewerID)
 	}
-
-	json.NewEncoder(w).Encode(hrs)
-}
-
-func UpdateInterview(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id := params["id"]
-
-	var interview Interview
-	var interviewer Interviewer
-	var candidate Candidate
-	var hr HR
-
-	err := json.NewDecoder(r.Body).Decode(&interview)
+	err = interview.normalizePanel()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	stmt, err := db.Prepare("UPDATE interview SET interviewer_id=?, candidate_id=?, hr_id=?, scheduled_time=?, rescheduled=?, interview_link=? WHERE id=?")
+	err = interview.normalizeTimes(s.scheduling.DefaultDuration)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(interview.InterviewerID, interview.CandidateID, interview.HRID, interview.ScheduledTime, interview.Rescheduled, interview.InterviewLink, id)
+	_, conflicts, err := s.reschedule(r.Context(), id, &interview, req.Reason, req.Actor)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else if errors.Is(err, ErrInvalidTransition) || errors.Is(err, ErrInvalidLoop) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else if errors.Is(err, ErrUnknownParticipant) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
-
-	interviewer, err = GetInterviewerByID(interview.InterviewerID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if len(conflicts) > 0 {
+		sendConflicts(w, conflicts)
 		return
 	}
 
-	// err = AddOutgoingCallerID(twilioConfig.AccountSID, twilioConfig.AuthToken, interviewer.PhoneNumber)
-	// if err != nil {
-	// 	fmt.Println("Error:", err)
-	// 	return
-	// }
-
-	// fmt.Println("Phone number added to valid caller IDs successfully.")
-
 	// Get the HR's phone number
-	hr, err = GetHRByID(interview.HRID)
+	hr, err := s.hrs.GetHR(r.Context(), interview.HRID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// err = AddOutgoingCallerID(twilioConfig.AccountSID, twilioConfig.AuthToken, hr.PhoneNumber)
-	// if err != nil {
-	// 	fmt.Println("Error:", err)
-	// 	return
-	// }
-
-	// fmt.Println("Phone number added to valid caller IDs successfully.")
-
 	// Get the candidate's phone number
-	candidate, err = GetCandidateByID(interview.CandidateID)
-
+	candidate, err := s.candidates.GetCandidate(r.Context(), interview.CandidateID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = publishMessage(interviewer.PhoneNumber, "Updated interview schedule :  "+interview.ScheduledTime+" "+interview.InterviewLink)
+	err = s.notifyPanel(r.Context(), interview, EventUpdated, "Updated interview schedule :  ")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = publishMessage(hr.PhoneNumber, "Updated interview schedule :  "+interview.ScheduledTime+" "+interview.InterviewLink)
+	err = s.notify(r.Context(), hr.recipient(), "Updated interview schedule :  "+localTime(interview.ScheduledTime, hr.TimeZone)+" "+interview.InterviewLink, interviewMetadata(EventUpdated, interview))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = publishMessage(candidate.PhoneNumber, "Updated interview schedule :  "+interview.ScheduledTime+" "+interview.InterviewLink)
+	err = s.notify(r.Context(), candidate.recipient(), "Updated interview schedule :  "+localTime(interview.ScheduledTime, candidate.TimeZone)+" "+interview.InterviewLink+s.manageNote(interview), interviewMetadata(EventUpdated, interview))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -677,254 +599,167 @@ func UpdateInterview(w http.ResponseWriter, r *http.Request) {
 	// w.WriteHeader(http.StatusOK)
 }
 
-func DeleteInterview(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	id := params["id"]
-
-	stmt, err := db.Prepare("DELETE FROM interview WHERE id=?")
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// DeleteInterview cancels the interview, keeping it and its history, and
+// tells everyone taking part. DELETE /admin/interview/{id} removes it.
+func (s *Server) DeleteInterview(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
 		return
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	var req statusRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	SendResponse(map[string]string{"message": "Interview is been deleted Successfully"}, w)
-	// w.WriteHeader(http.StatusOK)
-}
-
-func GetAllInterviews(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT * FROM interview")
+	interview, err := s.changeStatus(r.Context(), id, StatusCancelled, req.Reason, req.Actor, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var interviews []Interview
-
-	for rows.Next() {
-		var interview Interview
-		err := rows.Scan(&interview.ID, &interview.InterviewerID, &interview.CandidateID, &interview.HRID, &interview.ScheduledTime, &interview.Rescheduled, &interview.InterviewLink)
-		if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else if errors.Is(err, ErrInvalidTransition) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
 		}
-		interviews = append(interviews, interview)
-	}
-
-	if err = rows.Err(); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(interviews)
-}
-
-func GetCandidateByID(candidateID int) (Candidate, error) {
-
-	query := "SELECT id, name, phone_number FROM candidate WHERE id = ?"
-	row := db.QueryRow(query, candidateID)
-
-	candidate := Candidate{}
-	err := row.Scan(&candidate.ID, &candidate.Name, &candidate.PhoneNumber)
+	err = s.notifyCancelled(r.Context(), interview, req.Reason)
 	if err != nil {
-		return candidate, err
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	return candidate, nil
+	SendResponse(map[string]string{"message": "Interview is been cancelled Successfully"}, w)
+	// w.WriteHeader(http.StatusOK)
 }
 
-func GetHRByID(hrID int) (HR, error) {
-
-	query := "SELECT id, name, phone_number FROM hr WHERE id = ?"
-	row := db.QueryRow(query, hrID)
-
-	hr := HR{}
-	err := row.Scan(&hr.ID, &hr.Name, &hr.PhoneNumber)
+func (s *Server) GetAllInterviews(w http.ResponseWriter, r *http.Request) {
+	statuses, err := parseStatuses(r.URL.Query().Get("status"))
 	if err != nil {
-		return hr, err
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	return hr, nil
-}
-
-func GetInterviewerByID(interviewerID int) (Interviewer, error) {
-
-	query := "SELECT id, name, phone_number FROM interviewer WHERE id = ?"
-	row := db.QueryRow(query, interviewerID)
-
-	interviewer := Interviewer{}
-	err := row.Scan(&interviewer.ID, &interviewer.Name, &interviewer.PhoneNumber)
+	interviews, err := s.interviews.ListInterviews(r.Context(), statuses...)
 	if err != nil {
-		return interviewer, err
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	return interviewer, nil
+	json.NewEncoder(w).Encode(interviews)
 }
 
-func createInterview(w http.ResponseWriter, r *http.Request) {
-	var interview Interview
-	err := json.NewDecoder(r.Body).Decode(&interview)
+func (s *Server) createInterview(w http.ResponseWriter, r *http.Request) {
+	var req createInterviewRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	interview := req.Interview
+	autoAssign := interview.InterviewerID == 0 && req.Assign != nil
 
-	if interview.InterviewerID == 0 || interview.CandidateID == 0 || interview.HRID == 0 {
+	if (interview.InterviewerID == 0 && len(interview.Panel) == 0 && !autoAssign) || interview.CandidateID == 0 || interview.HRID == 0 {
 		http.Error(w, "Interviewer, candidate, or HR ID is missing or zero", http.StatusBadRequest)
 		return
 	}
 
-	conflict, err := hasConflictingSchedule(interview.InterviewerID, interview.ScheduledTime)
-	if err != nil {
-		http.Error(w, "Failed to check conflicting schedules", http.StatusInternalServerError)
-		return
-	}
-	if conflict {
-		http.Error(w, "Interviewer has another interview scheduled at the same time", http.StatusBadRequest)
-		return
+	if !autoAssign {
+		err = interview.normalizePanel()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 
-	conflict, err = hasConflictingSchedule(interview.HRID, interview.ScheduledTime)
-	if err != nil {
-		http.Error(w, "Failed to check conflicting schedules", http.StatusInternalServerError)
-		return
+	if autoAssign && req.Assign.windowed() && interview.ScheduledTime.IsZero() {
+		// assign moves it to the first free slot of the window.
+		interview.ScheduledTime = req.Assign.From
 	}
-	if conflict {
-		http.Error(w, "HR has another interview scheduled at the same time", http.StatusBadRequest)
-		return
-	}
-
-	conflict, err = hasConflictingSchedule(interview.CandidateID, interview.ScheduledTime)
+	err = interview.normalizeTimes(s.scheduling.DefaultDuration)
 	if err != nil {
-		http.Error(w, "Failed to check conflicting schedules", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if conflict {
-		http.Error(w, "Candidate has another interview scheduled at the same time", http.StatusBadRequest)
-		return
+	var assignment Assignment
+	var conflicts []Conflict
+	if autoAssign {
+		assignment, conflicts, err = s.assign(r.Context(), &interview, *req.Assign)
+	} else {
+		conflicts, err = s.book(r.Context(), &interview)
 	}
-
-	stmt, err := db.Prepare("INSERT INTO interview (id, interviewer_id, candidate_id, hr_id, scheduled_time, rescheduled, interview_link) VALUES (?, ?, ?, ?, ?, ?, ?)")
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if errors.Is(err, ErrNoInterviewerFree) {
+		sendConflictsMessage(w, err.Error(), conflicts)
 		return
 	}
-	defer stmt.Close()
-
-	_, err = stmt.Exec(interview.ID, interview.InterviewerID, interview.CandidateID, interview.HRID, interview.ScheduledTime, interview.Rescheduled, interview.InterviewLink)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, ErrUnknownParticipant) || errors.Is(err, ErrUnknownStrategy) || errors.Is(err, ErrNoEligibleInterviewer) || errors.Is(err, ErrInvalidPanel) || errors.Is(err, ErrInvalidWindow) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
-
-	interviewer, err := GetInterviewerByID(interview.InterviewerID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if len(conflicts) > 0 {
+		sendConflicts(w, conflicts)
 		return
 	}
 
-	// err = AddOutgoingCallerID(twilioConfig.AccountSID, twilioConfig.AuthToken, interviewer.PhoneNumber)
-	// if err != nil {
-	// 	fmt.Println("Error:", err)
-	// 	return
-	// }
-
-	// fmt.Println("Phone number added to valid caller IDs successfully.")
-
 	// Get the HR's phone number
-	hr, err := GetHRByID(interview.HRID)
+	hr, err := s.hrs.GetHR(r.Context(), interview.HRID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// err = AddOutgoingCallerID(twilioConfig.AccountSID, twilioConfig.AuthToken, hr.PhoneNumber)
-	// if err != nil {
-	// 	fmt.Println("Error:", err)
-	// 	return
-	// }
-
-	// fmt.Println("Phone number added to valid caller IDs successfully.")
-
 	// Get the candidate's phone number
-	candidate, err := GetCandidateByID(interview.CandidateID)
+	candidate, err := s.candidates.GetCandidate(r.Context(), interview.CandidateID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// err = AddOutgoingCallerID(twilioConfig.AccountSID, twilioConfig.AuthToken, hr.PhoneNumber)
-	// if err != nil {
-	// 	fmt.Println("Error:", err)
-	// 	return
-	// }
-
-	// fmt.Println("Phone number added to valid caller IDs successfully.")
-
 	interviewLink := generateInterviewLink()
 	fmt.Println("Random interview link:", interviewLink)
 
-	err = publishMessage(interviewer.PhoneNumber, "You have an interview scheduled at "+interview.ScheduledTime+" "+interview.InterviewLink)
+	err = s.notifyPanel(r.Context(), interview, EventScheduled, "You have an interview scheduled at ")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = publishMessage(hr.PhoneNumber, "You have an interview scheduled at "+interview.ScheduledTime+" "+interview.InterviewLink)
+	err = s.notify(r.Context(), hr.recipient(), "You have an interview scheduled at "+localTime(interview.ScheduledTime, hr.TimeZone)+" "+interview.InterviewLink, interviewMetadata(EventScheduled, interview))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = publishMessage(candidate.PhoneNumber, "You have an interview scheduled at "+interview.ScheduledTime+" "+interview.InterviewLink)
+	err = s.notify(r.Context(), candidate.recipient(), "You have an interview scheduled at "+localTime(interview.ScheduledTime, candidate.TimeZone)+" "+interview.InterviewLink+s.manageNote(interview), interviewMetadata(EventScheduled, interview))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if autoAssign {
+		SendResponse(map[string]any{"message": "Interview Created Successfully", "assignment": assignment, "scheduled_time": interview.ScheduledTime}, w)
+		return
+	}
 	SendResponse(map[string]string{"message": "Interview Created Successfully"}, w)
 	// w.WriteHeader(http.StatusCreated)
 }
 
-func publishMessage(phoneNumber, message string) error {
-
+func startPubSubSubscriber(notifiers Notifiers) {
 	ctx := context.Background()
 
-	client, err := pubsub.NewClient(ctx, pubsubConfig.ProjectID)
-	if err != nil {
-		return err
-	}
-
-	topic := client.Topic(pubsubConfig.TopicID)
-
-	msg := &pubsub.Message{
-		Data: []byte(fmt.Sprintf(`{"phone_number": "%s", "message": "%s"}`, phoneNumber, message)),
-	}
-
-	_, err = topic.Publish(ctx, msg).Get(ctx)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func startPubSubSubscriber() {
-	ctx := context.Background()
-
-	credentialsPath := "/Users/sanjanajadhav/Downloads/key.json"
-
-	err := os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credentialsPath)
-	if err != nil {
-		log.Fatal("Failed to set GOOGLE_APPLICATION_CREDENTIALS:", err)
+	if pubsubConfig.CredentialsFile != "" {
+		err := os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", pubsubConfig.CredentialsFile)
+		if err != nil {
+			log.Fatal("Failed to set GOOGLE_APPLICATION_CREDENTIALS:", err)
+		}
 	}
 
 	client, err := pubsub.NewClient(ctx, pubsubConfig.ProjectID)
@@ -934,24 +769,9 @@ func startPubSubSubscriber() {
 
 	sub := client.Subscription(pubsubConfig.SubscriptionID)
 	err = sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
-		// Retrieve the phone number and message from the Pub/Sub message
-		var pubsubMessage struct {
-			PhoneNumber string `json:"phone_number"`
-			Message     string `json:"message"`
-		}
-
-		err := json.Unmarshal(msg.Data, &pubsubMessage)
+		err := notifiers.deliver(ctx, msg.Data)
 		if err != nil {
-			log.Println("Failed to unmarshal Pub/Sub message:", err)
-			msg.Ack()
-			return
-		}
-
-		err = sendSMS(pubsubMessage.PhoneNumber, pubsubMessage.Message)
-		if err != nil {
-			log.Println("Failed to send SMS:", err)
-			msg.Ack()
-			return
+			log.Println("Failed to send notification:", err)
 		}
 
 		// Acknowledge the message
@@ -962,16 +782,25 @@ func startPubSubSubscriber() {
 	}
 }
 
-func sendSMS(phoneNumber, message string) error {
+// smsNotifier sends notifications as text messages through Twilio.
+type smsNotifier struct {
+	config TwilioConfig
+}
+
+func (n smsNotifier) Address(r Recipient) string {
+	return r.PhoneNumber
+}
+
+func (n smsNotifier) Send(ctx context.Context, notification Notification) error {
 	client := twilio.NewRestClientWithParams(twilio.ClientParams{
-		Username: twilioConfig.AccountSID,
-		Password: twilioConfig.AuthToken,
+		Username: n.config.AccountSID,
+		Password: n.config.AuthToken,
 	})
 
 	params := &twilioApi.CreateMessageParams{
-		From: &twilioConfig.PhoneNumber,
-		To:   &phoneNumber,
-		Body: &message,
+		From: &n.config.PhoneNumber,
+		To:   &notification.Recipient,
+		Body: &notification.Message,
 	}
 
 	_, err := client.Api.CreateMessage(params)
@@ -981,29 +810,6 @@ func sendSMS(phoneNumber, message string) error {
 	return nil
 }
 
-func hasConflictingSchedule(userID int, scheduledTime string) (bool, error) {
-	db, err := sql.Open("mysql", "root:Sanju2001@/interview_system?charset=utf8&parseTime=True&loc=Local")
-	if err != nil {
-		return false, err
-	}
-	defer db.Close()
-
-	query := "SELECT COUNT(*) FROM interview WHERE (interviewer_id = ? OR hr_id = ? OR candidate_id = ?) AND scheduled_time = ?"
-	stmt, err := db.Prepare(query)
-	if err != nil {
-		return false, err
-	}
-	defer stmt.Close()
-
-	var count int
-	err = stmt.QueryRow(userID, userID, userID, scheduledTime).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-
-	return count > 0, nil
-}
-
 func AddOutgoingCallerID(accountSid, authToken, phoneNumber string) error {
 
 	client := resty.New()
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// actorCandidate is the actor recorded in the reschedule and status history
+// for changes candidates make through their manage link.
+const actorCandidate = "candidate"
+
+var (
+	// ErrChangeTooLate is returned when a candidate tries to change an
+	// interview within the change cutoff of its start.
+	ErrChangeTooLate = errors.New("the interview starts too soon to be changed")
+	// ErrRescheduleLimit is returned when a candidate has used up their
+	// reschedules.
+	ErrRescheduleLimit = errors.New("the interview cannot be rescheduled again")
+)
+
+// ManageLink is what a manage token carries. It lets the candidate of one
+// interview reschedule or cancel it, subject to the booking policy.
+type ManageLink struct {
+	InterviewID int `json:"interview_id"`
+	CandidateID int `json:"candidate_id"`
+}
+
+// manageNote is appended to the candidate's notifications about interview,
+// pointing them to their manage link. It is empty while links are disabled.
+func (s *Server) manageNote(interview Interview) string {
+	if s.booking.Secret == "" || interview.ID == 0 {
+		return ""
+	}
+	token, err := signToken("manage", ManageLink{interview.ID, interview.CandidateID}, s.booking.Secret)
+	if err != nil {
+		return ""
+	}
+	return " Reschedule or cancel: " + s.linkURL("manage", token)
+}
+
+// candidateReschedules counts the reschedules the candidate made.
+func candidateReschedules(reschedules []Reschedule) int {
+	n := 0
+	for _, r := range reschedules {
+		if r.Actor == actorCandidate {
+			n++
+		}
+	}
+	return n
+}
+
+// changeDeadline is the last moment the candidate can change interview.
+func (s *Server) changeDeadline(interview Interview) time.Time {
+	return interview.ScheduledTime.Add(-s.booking.ChangeCutoff)
+}
+
+// checkChange enforces the change cutoff on an interview that is still
+// open.
+func (s *Server) checkChange(interview Interview, now time.Time) error {
+	if !interview.open() {
+		return fmt.Errorf("%w: the interview is %s", ErrInvalidTransition, interview.Status)
+	}
+	if now.After(s.changeDeadline(interview)) {
+		return fmt.Errorf("%w: changes must be made at least %s before the start", ErrChangeTooLate, s.booking.ChangeCutoff)
+	}
+	return nil
+}
+
+// checkCandidateReschedule enforces the change cutoff and the reschedule
+// limit for interview.
+func (s *Server) checkCandidateReschedule(ctx context.Context, st InterviewStore, interview Interview, now time.Time) error {
+	if err := s.checkChange(interview, now); err != nil {
+		return err
+	}
+	reschedules, err := st.ListReschedules(ctx, interview.ID)
+	if err != nil {
+		return err
+	}
+	if candidateReschedules(reschedules) >= s.booking.MaxReschedules {
+		return fmt.Errorf("%w: it has been rescheduled the maximum of %d times", ErrRescheduleLimit, s.booking.MaxReschedules)
+	}
+	return nil
+}
+
+// manageFromRequest verifies the {token} route variable and loads the
+// interview it refers to, writing the error response when it cannot.
+func (s *Server) manageFromRequest(w http.ResponseWriter, r *http.Request) (ManageLink, Interview, bool) {
+	var link ManageLink
+	if s.booking.Secret == "" {
+		http.NotFound(w, r)
+		return link, Interview{}, false
+	}
+	if err := verifyToken("manage", mux.Vars(r)["token"], s.booking.Secret, &link); err != nil {
+		http.NotFound(w, r)
+		return link, Interview{}, false
+	}
+
+	interview, err := s.interviews.GetInterview(r.Context(), link.InterviewID)
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, "The interview no longer exists", http.StatusGone)
+		return link, interview, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return link, interview, false
+	}
+	if interview.CandidateID != link.CandidateID {
+		http.NotFound(w, r)
+		return link, interview, false
+	}
+	return link, interview, true
+}
+
+// sendPolicyError writes the response for a change refused by the policy,
+// by the interview's status or by the ordering of its loop.
+func sendPolicyError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, ErrChangeTooLate) || errors.Is(err, ErrRescheduleLimit):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrInvalidTransition) || errors.Is(err, ErrInvalidLoop):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) getManageLink(w http.ResponseWriter, r *http.Request) {
+	_, interview, ok := s.manageFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	candidate, err := s.candidates.GetCandidate(r.Context(), interview.CandidateID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc := location(candidate.TimeZone)
+
+	reschedules, err := s.interviews.ListReschedules(r.Context(), interview.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	left := s.booking.MaxReschedules - candidateReschedules(reschedules)
+	if left < 0 {
+		left = 0
+	}
+
+	now := time.Now().UTC()
+	canChange := s.checkChange(interview, now) == nil
+	canReschedule := canChange && left > 0
+
+	// Offer free slots from the earliest time a new slot is accepted.
+	slots := []Suggestion{}
+	if canReschedule {
+		from := now.Add(s.booking.ChangeCutoff)
+		free, err := s.freeTime(r.Context(), interview.participants(), from, from.Add(7*24*time.Hour))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		slots = suggestSlots(free, interview.Duration(), defaultSuggestions)
+		for i := range slots {
+			slots[i].ScheduledTime = slots[i].ScheduledTime.In(loc)
+			slots[i].EndTime = slots[i].EndTime.In(loc)
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"status":           interview.Status,
+		"scheduled_time":   interview.ScheduledTime.In(loc),
+		"end_time":         interview.EndTime.In(loc),
+		"duration_minutes": interview.DurationMinutes,
+		"time_zone":        candidate.TimeZone,
+		"change_deadline":  s.changeDeadline(interview).In(loc),
+		"can_cancel":       canChange,
+		"can_reschedule":   canReschedule,
+		"reschedules_left": left,
+		"slots":            slots,
+	})
+}
+
+// manageRescheduleRequest is the body of POST /manage/{token}/reschedule.
+type manageRescheduleRequest struct {
+	ScheduledTime time.Time `json:"scheduled_time"`
+	Reason        string    `json:"reason"`
+}
+
+func (s *Server) rescheduleFromLink(w http.ResponseWriter, r *http.Request) {
+	link, existing, ok := s.manageFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req manageRescheduleRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now().UTC()
+	if req.ScheduledTime.IsZero() {
+		http.Error(w, "scheduled_time is required", http.StatusBadRequest)
+		return
+	}
+	if req.ScheduledTime.Before(now.Add(s.booking.ChangeCutoff)) {
+		http.Error(w, fmt.Sprintf("scheduled_time must be at least %s from now", s.booking.ChangeCutoff), http.StatusBadRequest)
+		return
+	}
+	if req.ScheduledTime.Equal(existing.ScheduledTime) {
+		http.Error(w, "The interview is already scheduled at that time", http.StatusBadRequest)
+		return
+	}
+
+	interview := existing
+	interview.ScheduledTime, interview.EndTime = req.ScheduledTime, time.Time{}
+	err = interview.normalizeTimes(s.scheduling.DefaultDuration)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	previous, conflicts, err := s.rescheduleWith(r.Context(), link.InterviewID, &interview, req.Reason, actorCandidate, func(tx Store, current Interview) error {
+		if current.CandidateID != link.CandidateID {
+			return ErrNotFound
+		}
+		return s.checkCandidateReschedule(r.Context(), tx, current, now)
+	})
+	if sendPolicyError(w, err) {
+		return
+	}
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, "The interview no longer exists", http.StatusGone)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	// The candidate is not told who else is busy, only that the slot is taken.
+	if len(conflicts) > 0 {
+		http.Error(w, "That time is not available, please pick another one", http.StatusConflict)
+		return
+	}
+
+	candidate, err := s.candidates.GetCandidate(r.Context(), interview.CandidateID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	change := func(zone string) string {
+		message := fmt.Sprintf("%s rescheduled their interview from %s to %s %s",
+			candidate.Name, localTime(previous.ScheduledTime, zone), localTime(interview.ScheduledTime, zone), interview.InterviewLink)
+		if req.Reason != "" {
+			message += ". Reason: " + req.Reason
+		}
+		return message
+	}
+	err = s.notifyCandidateChange(r.Context(), interview, EventUpdated, change)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = s.notify(r.Context(), candidate.recipient(), "Updated interview schedule :  "+localTime(interview.ScheduledTime, candidate.TimeZone)+" "+interview.InterviewLink+s.manageNote(interview), interviewMetadata(EventUpdated, interview))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	loc := location(candidate.TimeZone)
+	SendResponse(map[string]any{
+		"message":        "Interview Rescheduled Successfully",
+		"scheduled_time": interview.ScheduledTime.In(loc),
+		"end_time":       interview.EndTime.In(loc),
+	}, w)
+}
+
+// manageCancelRequest is the body of POST /manage/{token}/cancel.
+type manageCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (s *Server) cancelFromLink(w http.ResponseWriter, r *http.Request) {
+	link, interview, ok := s.manageFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req manageCancelRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	interview, err = s.changeStatus(r.Context(), link.InterviewID, StatusCancelled, req.Reason, actorCandidate, func(existing Interview) error {
+		if existing.CandidateID != link.CandidateID {
+			return ErrNotFound
+		}
+		return s.checkChange(existing, now)
+	})
+	if sendPolicyError(w, err) {
+		return
+	}
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, "The interview no longer exists", http.StatusGone)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	candidate, err := s.candidates.GetCandidate(r.Context(), interview.CandidateID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = s.notifyCandidateChange(r.Context(), interview, EventCancelled, func(zone string) string {
+		return fmt.Sprintf("%s cancelled their interview at %s. Reason: %s", candidate.Name, localTime(interview.ScheduledTime, zone), req.Reason)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = s.notify(r.Context(), candidate.recipient(), "Your interview at "+localTime(interview.ScheduledTime, candidate.TimeZone)+" has been cancelled", interviewMetadata(EventCancelled, interview))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	SendResponse(map[string]string{"message": "Interview Cancelled Successfully"}, w)
+}
+
+// notifyCandidateChange tells the HR and the panel of interview about a
+// change the candidate made, with message built for each recipient's time
+// zone and event as metadata.
+func (s *Server) notifyCandidateChange(ctx context.Context, interview Interview, event string, message func(zone string) string) error {
+	metadata := interviewMetadata(event, interview)
+	hr, err := s.hrs.GetHR(ctx, interview.HRID)
+	if err != nil {
+		return err
+	}
+	if err := s.notify(ctx, hr.recipient(), message(hr.TimeZone), metadata); err != nil {
+		return err
+	}
+	for _, p := range interview.Panel {
+		if err := s.notifyInterviewer(ctx, p.InterviewerID, metadata, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
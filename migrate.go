@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"example.com/database"
+)
+
+const migrateUsage = "usage: interview-system migrate up|down|status [flags]"
+
+// runMigrate implements the migrate subcommand. args are the arguments
+// following "migrate".
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+	command := args[0]
+
+	cfg, err := loadDatabaseConfig(args[1:])
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := database.MigrateUp(ctx, db, cfg.Store)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+	case "down":
+		m, err := database.MigrateDown(ctx, db, cfg.Store)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("No migrations to roll back")
+		} else {
+			fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+		}
+	case "status":
+		status, err := database.Status(ctx, db, cfg.Store)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", command, migrateUsage)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// ChannelSMS delivers notifications as text messages through Twilio. It is
+// always registered and is the channel of people who enabled none.
+const ChannelSMS = "sms"
+
+// Notification events, sent as the "event" metadata of notifications so
+// channels that render more than the text can tell them apart.
+const (
+	EventScheduled = "interview_scheduled"
+	EventUpdated   = "interview_updated"
+	EventCancelled = "interview_cancelled"
+	// EventPanelRemoved tells an interviewer they were taken off the panel
+	// of an interview that still goes ahead. Its metadata also carries the
+	// interviewer_id and panel_role they had.
+	EventPanelRemoved = "interview_panel_removed"
+)
+
+// Notification is one message to one recipient over one channel. It is what
+// is queued on Pub/Sub and handed to the channel's Notifier.
+type Notification struct {
+	Channel string `json:"channel"`
+	// Recipient is the address on the channel, e.g. a phone number for SMS.
+	Recipient string `json:"recipient"`
+	// Message is the rendered text, already in the recipient's time zone.
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Calendar is the iCalendar invite of the interview the notification
+	// is about, for channels that attach one.
+	Calendar string `json:"calendar,omitempty"`
+}
+
+// Recipient is a person notifications are sent to.
+type Recipient struct {
+	Name        string
+	PhoneNumber string
+	Email       string
+	TimeZone    string
+	// Channels lists the channels they are notified on.
+	Channels []string
+}
+
+// Notifier delivers notifications over one channel.
+type Notifier interface {
+	// Address returns where r is reached on the channel, or "" when they
+	// cannot be reached on it.
+	Address(r Recipient) string
+	// Send delivers n.
+	Send(ctx context.Context, n Notification) error
+}
+
+// Notifiers is the registry of channels, keyed by channel name.
+type Notifiers map[string]Notifier
+
+// newNotifiers registers every channel cfg enables.
+func newNotifiers(cfg Config) Notifiers {
+	notifiers := Notifiers{
+		ChannelSMS: smsNotifier{cfg.Twilio},
+	}
+	if cfg.SMTP.Host != "" {
+		notifiers[ChannelEmail] = emailNotifier{cfg.SMTP}
+	}
+	return notifiers
+}
+
+// names returns the registered channel names, sorted.
+func (n Notifiers) names() []string {
+	names := make([]string, 0, len(n))
+	for name := range n {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizeChannels checks that every channel of r is registered and has
+// an address for them, and drops duplicates. No channels means SMS only.
+func (n Notifiers) normalizeChannels(r Recipient) ([]string, error) {
+	if len(r.Channels) == 0 {
+		return []string{ChannelSMS}, nil
+	}
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, channel := range r.Channels {
+		notifier, ok := n[channel]
+		if !ok {
+			return nil, fmt.Errorf("notification channel %q is not one of %s", channel, strings.Join(n.names(), ", "))
+		}
+		if notifier.Address(r) == "" {
+			return nil, fmt.Errorf("notification channel %q has no address to send to", channel)
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			normalized = append(normalized, channel)
+		}
+	}
+	return normalized, nil
+}
+
+func (i Interviewer) recipient() Recipient {
+	return Recipient{i.Name, i.PhoneNumber, i.Email, i.TimeZone, i.NotificationChannels}
+}
+
+func (c Candidate) recipient() Recipient {
+	return Recipient{c.Name, c.PhoneNumber, c.Email, c.TimeZone, c.NotificationChannels}
+}
+
+func (h HR) recipient() Recipient {
+	return Recipient{h.Name, h.PhoneNumber, h.Email, h.TimeZone, h.NotificationChannels}
+}
+
+// interviewMetadata describes interview to the channels, under event.
+func interviewMetadata(event string, interview Interview) map[string]string {
+	return map[string]string{
+		"event":          event,
+		"interview_id":   strconv.Itoa(interview.ID),
+		"scheduled_time": interview.ScheduledTime.Format(time.RFC3339),
+		"end_time":       interview.EndTime.Format(time.RFC3339),
+		"interview_link": interview.InterviewLink,
+	}
+}
+
+// notify queues message for r on each channel they have enabled. Channels
+// that are no longer registered, or on which r has no address, are skipped.
+func (s *Server) notify(ctx context.Context, r Recipient, message string, metadata map[string]string) error {
+	channels := r.Channels
+	if len(channels) == 0 {
+		channels = []string{ChannelSMS}
+	}
+	var calendar string
+	for _, channel := range channels {
+		notifier, ok := s.notifiers[channel]
+		if !ok {
+			log.Printf("Skipping notification to %s: channel %q is not enabled", r.Name, channel)
+			continue
+		}
+		address := notifier.Address(r)
+		if address == "" {
+			continue
+		}
+		notification := Notification{
+			Channel:   channel,
+			Recipient: address,
+			Message:   message,
+			Metadata:  metadata,
+		}
+		if _, ok := notifier.(calendarNotifier); ok {
+			if calendar == "" {
+				var err error
+				calendar, err = s.notificationCalendar(ctx, metadata)
+				if err != nil {
+					return err
+				}
+			}
+			notification.Calendar = calendar
+		}
+		if err := s.publish(ctx, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishNotification queues n on the notification topic; the subscriber
+// started by startPubSubSubscriber delivers it.
+func publishNotification(ctx context.Context, n Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	client, err := pubsub.NewClient(ctx, pubsubConfig.ProjectID)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	topic := client.Topic(pubsubConfig.TopicID)
+	_, err = topic.Publish(ctx, &pubsub.Message{Data: data}).Get(ctx)
+	return err
+}
+
+// deliver hands a queued notification to its channel. Messages queued
+// before channels existed carry only a phone number and go out as SMS.
+func (n Notifiers) deliver(ctx context.Context, data []byte) error {
+	var notification struct {
+		Notification
+		PhoneNumber string `json:"phone_number"`
+	}
+	err := json.Unmarshal(data, &notification)
+	if err != nil {
+		return err
+	}
+	if notification.Channel == "" {
+		notification.Channel = ChannelSMS
+		notification.Recipient = notification.PhoneNumber
+	}
+
+	notifier, ok := n[notification.Channel]
+	if !ok {
+		return fmt.Errorf("notification channel %q is not enabled", notification.Channel)
+	}
+	return notifier.Send(ctx, notification.Notification)
+}
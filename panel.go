@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ErrInvalidPanel is returned for a panel that breaks the rules enforced by
+// normalizePanel.
+var ErrInvalidPanel = errors.New("invalid panel")
+
+// Panel roles. Every panel has exactly one lead, who is also the
+// interview's InterviewerID.
+const (
+	PanelLead      = "lead"
+	PanelShadow    = "shadow"
+	PanelNoteTaker = "note_taker"
+)
+
+// Panelist is one interviewer on an interview's panel.
+type Panelist struct {
+	InterviewerID int    `json:"interviewer_id"`
+	Role          string `json:"role"`
+}
+
+// normalizePanel validates the panel and reconciles it with InterviewerID:
+// InterviewerID joins the panel as lead when it is not on it yet, and an
+// interview sent without InterviewerID takes the panel's lead. The lead is
+// moved to the front.
+func (i *Interview) normalizePanel() error {
+	if i.InterviewerID != 0 && !i.onPanel(i.InterviewerID) {
+		i.Panel = append([]Panelist{{i.InterviewerID, PanelLead}}, i.Panel...)
+	}
+	if len(i.Panel) == 0 {
+		return fmt.Errorf("%w: interviewer_id or panel is required", ErrInvalidPanel)
+	}
+
+	seen := map[int]bool{}
+	lead := 0
+	for n, p := range i.Panel {
+		if p.InterviewerID == 0 {
+			return fmt.Errorf("%w: every panelist needs an interviewer_id", ErrInvalidPanel)
+		}
+		if seen[p.InterviewerID] {
+			return fmt.Errorf("%w: interviewer %d is on the panel twice", ErrInvalidPanel, p.InterviewerID)
+		}
+		seen[p.InterviewerID] = true
+
+		if p.Role == "" && p.InterviewerID == i.InterviewerID {
+			i.Panel[n].Role = PanelLead
+		}
+		switch i.Panel[n].Role {
+		case PanelLead:
+			if lead != 0 {
+				return fmt.Errorf("%w: a panel has exactly one lead", ErrInvalidPanel)
+			}
+			lead = p.InterviewerID
+		case PanelShadow, PanelNoteTaker:
+		default:
+			return fmt.Errorf("%w: role %q must be one of %s, %s, %s", ErrInvalidPanel, p.Role, PanelLead, PanelShadow, PanelNoteTaker)
+		}
+	}
+
+	switch {
+	case lead == 0:
+		return fmt.Errorf("%w: a panel has exactly one lead", ErrInvalidPanel)
+	case i.InterviewerID == 0:
+		i.InterviewerID = lead
+	case i.InterviewerID != lead:
+		return fmt.Errorf("%w: interviewer_id must be the panel's lead", ErrInvalidPanel)
+	}
+	sort.SliceStable(i.Panel, func(a, b int) bool { return i.Panel[a].Role == PanelLead && i.Panel[b].Role != PanelLead })
+	return nil
+}
+
+// panelWithLead returns a copy of the panel with lead as its lead. A
+// previous lead is dropped, and so is lead's own former place on the panel.
+func (i Interview) panelWithLead(lead int) []Panelist {
+	panel := []Panelist{{lead, PanelLead}}
+	for _, p := range i.Panel {
+		if p.Role != PanelLead && p.InterviewerID != lead {
+			panel = append(panel, p)
+		}
+	}
+	return panel
+}
+
+// onPanel reports whether the interviewer is on the panel.
+func (i Interview) onPanel(interviewerID int) bool {
+	for _, p := range i.Panel {
+		if p.InterviewerID == interviewerID {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyPanel sends every panelist message followed by the interview time
+// in their time zone and the interview link.
+func (s *Server) notifyPanel(ctx context.Context, interview Interview, event, message string) error {
+	metadata := interviewMetadata(event, interview)
+	for _, p := range interview.Panel {
+		err := s.notifyInterviewer(ctx, p.InterviewerID, metadata, func(zone string) string {
+			return message + localTime(interview.ScheduledTime, zone) + " " + interview.InterviewLink
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyInterviewer sends the interviewer the message built for their time
+// zone.
+func (s *Server) notifyInterviewer(ctx context.Context, interviewerID int, metadata map[string]string, message func(zone string) string) error {
+	interviewer, err := s.interviewers.GetInterviewer(ctx, interviewerID)
+	if err != nil {
+		return err
+	}
+	return s.notify(ctx, interviewer.recipient(), message(interviewer.TimeZone), metadata)
+}
+
+// addPanelist puts panelist on interview id's panel after checking that they
+// are free for it, and returns the updated interview.
+func (s *Server) addPanelist(ctx context.Context, id int, panelist Panelist) (Interview, []Conflict, error) {
+	participant := Participant{RoleInterviewer, panelist.InterviewerID}
+
+	var interview Interview
+	var conflicts []Conflict
+	err := s.atomicallyOnInterview(ctx, id, []Participant{participant}, func(tx Store, existing Interview) error {
+		interview = existing
+		if !interview.open() {
+			return fmt.Errorf("%w: a %s interview cannot be changed", ErrInvalidTransition, interview.Status)
+		}
+		if interview.onPanel(panelist.InterviewerID) {
+			return fmt.Errorf("%w: interviewer %d is already on the panel", ErrInvalidPanel, panelist.InterviewerID)
+		}
+
+		var err error
+		conflicts, err = s.checkSlot(ctx, tx, []Participant{participant}, interview, id)
+		if err != nil || len(conflicts) > 0 {
+			return err
+		}
+
+		interview.Panel = append(interview.Panel, panelist)
+		return tx.UpdateInterview(ctx, id, interview)
+	})
+	return interview, conflicts, err
+}
+
+// removePanelist takes an interviewer other than the lead off interview id's
+// panel and returns the updated interview and the place they had on it.
+func (s *Server) removePanelist(ctx context.Context, id, interviewerID int) (Interview, Panelist, error) {
+	var interview Interview
+	var removed Panelist
+	err := s.atomicallyOnInterview(ctx, id, nil, func(tx Store, existing Interview) error {
+		interview = existing
+		if !interview.open() {
+			return fmt.Errorf("%w: a %s interview cannot be changed", ErrInvalidTransition, interview.Status)
+		}
+		if !interview.onPanel(interviewerID) {
+			return ErrNotFound
+		}
+		if interviewerID == interview.InterviewerID {
+			return fmt.Errorf("%w: the lead cannot be removed; change the interview's interviewer_id instead", ErrInvalidPanel)
+		}
+
+		var panel []Panelist
+		for _, p := range interview.Panel {
+			if p.InterviewerID == interviewerID {
+				removed = p
+			} else {
+				panel = append(panel, p)
+			}
+		}
+		interview.Panel = panel
+		return tx.UpdateInterview(ctx, id, interview)
+	})
+	return interview, removed, err
+}
+
+func (s *Server) createPanelist(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var panelist Panelist
+	err := json.NewDecoder(r.Body).Decode(&panelist)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if panelist.InterviewerID == 0 {
+		http.Error(w, "interviewer_id is required", http.StatusBadRequest)
+		return
+	}
+	switch panelist.Role {
+	case "":
+		panelist.Role = PanelShadow
+	case PanelShadow, PanelNoteTaker:
+	case PanelLead:
+		http.Error(w, "the lead is set through the interview's interviewer_id", http.StatusBadRequest)
+		return
+	default:
+		http.Error(w, fmt.Sprintf("role must be %s or %s", PanelShadow, PanelNoteTaker), http.StatusBadRequest)
+		return
+	}
+
+	interview, conflicts, err := s.addPanelist(r.Context(), id, panelist)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else if errors.Is(err, ErrInvalidTransition) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else if errors.Is(err, ErrUnknownParticipant) || errors.Is(err, ErrInvalidPanel) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if len(conflicts) > 0 {
+		sendConflicts(w, conflicts)
+		return
+	}
+
+	err = s.notifyInterviewer(r.Context(), panelist.InterviewerID, interviewMetadata(EventScheduled, interview), func(zone string) string {
+		return "You have been added to the panel of an interview scheduled at " + localTime(interview.ScheduledTime, zone) + " " + interview.InterviewLink
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	SendResponse(map[string]string{"message": "Panelist Added Successfully"}, w)
+}
+
+func (s *Server) deletePanelist(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	interviewerID, err := strconv.Atoi(mux.Vars(r)["interviewerId"])
+	if err != nil {
+		http.Error(w, "Invalid id: "+mux.Vars(r)["interviewerId"], http.StatusBadRequest)
+		return
+	}
+
+	interview, removed, err := s.removePanelist(r.Context(), id, interviewerID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else if errors.Is(err, ErrInvalidTransition) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else if errors.Is(err, ErrInvalidPanel) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	metadata := interviewMetadata(EventPanelRemoved, interview)
+	metadata["interviewer_id"] = strconv.Itoa(removed.InterviewerID)
+	metadata["panel_role"] = removed.Role
+	err = s.notifyInterviewer(r.Context(), interviewerID, metadata, func(zone string) string {
+		return "You have been removed from the panel of the interview scheduled at " + localTime(interview.ScheduledTime, zone)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	SendResponse(map[string]string{"message": "Panelist Removed Successfully"}, w)
+}
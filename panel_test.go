@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPanelOfClosedInterview(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"add", http.MethodPost, "/interview/1/panelists", `{"interviewer_id": 2, "role": "shadow"}`},
+		{"remove", http.MethodDelete, "/interview/1/panelists/2", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemoryStore()
+			seedPeople(t, ctx, store)
+			interview := testInterview(testTime)
+			if tt.name == "remove" {
+				interview.Panel = append(interview.Panel, Panelist{2, PanelShadow})
+			}
+			id, err := store.CreateInterview(ctx, interview)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.SetInterviewStatus(ctx, StatusChange{InterviewID: id, FromStatus: StatusScheduled, ToStatus: StatusCancelled, CreatedAt: testTime})
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, err := store.GetInterview(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, sent := newTestServer(store)
+
+			w := httptest.NewRecorder()
+			s.routes().ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if w.Code != http.StatusConflict {
+				t.Errorf("got status %d, want %d: %s", w.Code, http.StatusConflict, w.Body)
+			}
+			after, err := store.GetInterview(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(after.Panel) != len(before.Panel) || after.Sequence != before.Sequence {
+				t.Errorf("the panel changed from %+v to %+v", before.Panel, after.Panel)
+			}
+			if len(*sent) > 0 {
+				t.Errorf("sent %d notifications", len(*sent))
+			}
+		})
+	}
+}
+
+func TestConcurrentPanelChanges(t *testing.T) {
+	const added = 6
+
+	for _, backend := range storeBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.open(t)
+			seedPeople(t, ctx, store)
+			for id := 3; id < 3+added; id++ {
+				i := Interviewer{ID: id, Name: fmt.Sprintf("Panelist %d", id), PhoneNumber: fmt.Sprintf("+1555000100%d", id), TimeZone: "UTC", NotificationChannels: []string{ChannelSMS}}
+				if err := store.CreateInterviewer(ctx, i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			interview := testInterview(testTime)
+			interview.Panel = append(interview.Panel, Panelist{2, PanelShadow})
+			id, err := store.CreateInterview(ctx, interview)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, _ := newTestServer(store)
+			router := s.routes()
+
+			// Every request waits on start so that they all read the panel
+			// before any of them writes it back.
+			requests := []*http.Request{httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/interview/%d/panelists/2", id), nil)}
+			for i := 3; i < 3+added; i++ {
+				body := fmt.Sprintf(`{"interviewer_id": %d, "role": "shadow"}`, i)
+				requests = append(requests, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/interview/%d/panelists", id), strings.NewReader(body)))
+			}
+			start := make(chan struct{})
+			var wg sync.WaitGroup
+			for _, r := range requests {
+				wg.Add(1)
+				go func(r *http.Request) {
+					defer wg.Done()
+					<-start
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, r)
+					if w.Code != http.StatusOK {
+						t.Errorf("%s %s: unexpected response %d: %s", r.Method, r.URL, w.Code, w.Body)
+					}
+				}(r)
+			}
+			close(start)
+			wg.Wait()
+
+			stored, err := store.GetInterview(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.onPanel(2) || len(stored.Panel) != 1+added {
+				t.Errorf("got panel %+v, want the lead and interviewers 3 to %d", stored.Panel, 2+added)
+			}
+			for i := 3; i < 3+added; i++ {
+				if !stored.onPanel(i) {
+					t.Errorf("interviewer %d was lost from panel %+v", i, stored.Panel)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurrence is the subset of an RFC 5545 RRULE supported for bulk
+// scheduling, e.g. "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6".
+type recurrence struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    map[time.Weekday]bool
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRecurrence parses FREQ (HOURLY, DAILY or WEEKLY), INTERVAL, COUNT,
+// UNTIL and BYDAY.
+func parseRecurrence(rule string) (recurrence, error) {
+	r := recurrence{interval: 1}
+	for _, part := range strings.Split(strings.TrimPrefix(rule, "RRULE:"), ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(value)
+			if r.freq != "HOURLY" && r.freq != "DAILY" && r.freq != "WEEKLY" {
+				return r, fmt.Errorf("recurrence FREQ must be HOURLY, DAILY or WEEKLY, not %s", value)
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err != nil || r.interval < 1 {
+				return r, fmt.Errorf("recurrence INTERVAL must be a positive number, not %s", value)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err != nil || r.count < 1 {
+				return r, fmt.Errorf("recurrence COUNT must be a positive number, not %s", value)
+			}
+		case "UNTIL":
+			r.until, err = time.Parse("20060102T150405Z", value)
+			if err != nil {
+				r.until, err = time.Parse(time.RFC3339, value)
+			}
+			if err != nil {
+				return r, fmt.Errorf("recurrence UNTIL must be a UTC time such as 20261231T235959Z, not %s", value)
+			}
+		case "BYDAY":
+			r.byDay = map[time.Weekday]bool{}
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return r, fmt.Errorf("recurrence BYDAY has unknown day %s", day)
+				}
+				r.byDay[weekday] = true
+			}
+		default:
+			return r, fmt.Errorf("recurrence rule part %s is not supported", key)
+		}
+	}
+	if r.freq == "" {
+		return r, errors.New("recurrence FREQ is required")
+	}
+	return r, nil
+}
+
+// bounded reports whether the rule ends by itself.
+func (r recurrence) bounded() bool {
+	return r.count > 0 || !r.until.IsZero()
+}
+
+// occurrences returns up to max start times of the rule beginning at start,
+// with days and weeks counted in loc so that local times survive daylight
+// saving changes.
+func (r recurrence) occurrences(start time.Time, loc *time.Location, max int) []time.Time {
+	if r.count > 0 && r.count < max {
+		max = r.count
+	}
+	local := start.In(loc)
+	firstWeek := local.AddDate(0, 0, -int(local.Weekday()))
+
+	var times []time.Time
+	// Walking day by day (or hour by hour) with a bounded number of steps
+	// keeps a rule that rarely matches from looping for ever.
+	for step := 0; len(times) < max && step < 5*366*24; step++ {
+		var t time.Time
+		switch r.freq {
+		case "HOURLY":
+			t = start.Add(time.Duration(step*r.interval) * time.Hour)
+		default:
+			t = local.AddDate(0, 0, step)
+		}
+		if !r.until.IsZero() && t.After(r.until) {
+			break
+		}
+
+		switch r.freq {
+		case "DAILY":
+			if step%r.interval != 0 {
+				continue
+			}
+		case "WEEKLY":
+			week := daysBetween(firstWeek, t) / 7
+			if week%r.interval != 0 {
+				continue
+			}
+			if r.byDay == nil && t.Weekday() != local.Weekday() {
+				continue
+			}
+		}
+		if r.byDay != nil && !r.byDay[t.In(loc).Weekday()] {
+			continue
+		}
+		times = append(times, t.UTC())
+	}
+	return times
+}
+
+// daysBetween counts the calendar days from a to b, ignoring the time of
+// day and any daylight saving change in between.
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da) / (24 * time.Hour))
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"COUNT=3",
+		"FREQ",
+		"FREQ=MONTHLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=x",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=WEEKLY;BYDAY=MO,XX",
+		"FREQ=DAILY;BYMONTH=1",
+	} {
+		if _, err := parseRecurrence(rule); err == nil {
+			t.Errorf("parseRecurrence(%q) succeeded", rule)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	// testTime is a Monday.
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		zone  string
+		max   int
+		want  []string
+	}{
+		{
+			name: "daily count", rule: "FREQ=DAILY;COUNT=3",
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-05T10:00:00Z", "2030-03-06T10:00:00Z"},
+		},
+		{
+			name: "daily interval", rule: "FREQ=DAILY;INTERVAL=2;COUNT=3",
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-06T10:00:00Z", "2030-03-08T10:00:00Z"},
+		},
+		{
+			name: "hourly interval", rule: "FREQ=HOURLY;INTERVAL=2;COUNT=3",
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-04T12:00:00Z", "2030-03-04T14:00:00Z"},
+		},
+		{
+			name: "weekly", rule: "RRULE:FREQ=WEEKLY;COUNT=3",
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-11T10:00:00Z", "2030-03-18T10:00:00Z"},
+		},
+		{
+			name: "weekly by day", rule: "freq=weekly;byday=mo,we;count=4",
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-06T10:00:00Z", "2030-03-11T10:00:00Z", "2030-03-13T10:00:00Z"},
+		},
+		{
+			name: "fortnightly by day", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=2",
+			want: []string{"2030-03-05T10:00:00Z", "2030-03-19T10:00:00Z"},
+		},
+		{
+			name: "daily by day", rule: "FREQ=DAILY;BYDAY=MO,FR;COUNT=3",
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-08T10:00:00Z", "2030-03-11T10:00:00Z"},
+		},
+		{
+			name: "until on an occurrence", rule: "FREQ=DAILY;UNTIL=20300306T100000Z",
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-05T10:00:00Z", "2030-03-06T10:00:00Z"},
+		},
+		{
+			name: "until just before an occurrence", rule: "FREQ=DAILY;UNTIL=2030-03-06T09:59:59Z",
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-05T10:00:00Z"},
+		},
+		{
+			name: "until before the start", rule: "FREQ=DAILY;UNTIL=20300301T000000Z",
+			want: nil,
+		},
+		{
+			name: "unbounded", rule: "FREQ=DAILY", max: 2,
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-05T10:00:00Z"},
+		},
+		{
+			name: "count above max", rule: "FREQ=WEEKLY;COUNT=10", max: 2,
+			want: []string{"2030-03-04T10:00:00Z", "2030-03-11T10:00:00Z"},
+		},
+		{
+			// New York moves to daylight saving time on 10 March 2030; a
+			// daily 10:00 interview stays at 10:00 local time.
+			name: "daylight saving change", rule: "FREQ=DAILY;COUNT=4",
+			start: time.Date(2030, 3, 8, 15, 0, 0, 0, time.UTC), zone: "America/New_York",
+			want: []string{"2030-03-08T15:00:00Z", "2030-03-09T15:00:00Z", "2030-03-10T14:00:00Z", "2030-03-11T14:00:00Z"},
+		},
+		{
+			// Monday 01:00 in Kolkata is Sunday in UTC; BYDAY follows the
+			// local day.
+			name: "by day in a zone", rule: "FREQ=WEEKLY;BYDAY=MO;COUNT=2",
+			start: time.Date(2030, 3, 3, 19, 30, 0, 0, time.UTC), zone: "Asia/Kolkata",
+			want: []string{"2030-03-03T19:30:00Z", "2030-03-10T19:30:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start, zone, max := tt.start, tt.zone, tt.max
+			if start.IsZero() {
+				start = testTime
+			}
+			if zone == "" {
+				zone = "UTC"
+			}
+			if max == 0 {
+				max = 100
+			}
+			got := r.occurrences(start, location(zone), max)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				want, _ := time.Parse(time.RFC3339, tt.want[i])
+				if !got[i].Equal(want) || got[i].Location() != time.UTC {
+					t.Errorf("occurrence %d: got %s, want %s", i, got[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Reschedule records one change to an interview's time.
+type Reschedule struct {
+	ID                      int       `json:"id"`
+	InterviewID             int       `json:"interview_id"`
+	PreviousTime            time.Time `json:"previous_time"`
+	PreviousDurationMinutes int       `json:"previous_duration_minutes"`
+	NewTime                 time.Time `json:"new_time"`
+	NewDurationMinutes      int       `json:"new_duration_minutes"`
+	Reason                  string    `json:"reason"`
+	Actor                   string    `json:"actor"`
+	CreatedAt               time.Time `json:"created_at"`
+}
+
+// updateInterviewRequest is the body of PUT /interview/{id}. Reason and
+// Actor are recorded in the reschedule history when the time changes.
+type updateInterviewRequest struct {
+	Interview
+	Reason string `json:"reason"`
+	Actor  string `json:"actor"`
+}
+
+// timeChanged reports whether updated moves the interview or changes its
+// length.
+func timeChanged(existing, updated Interview) bool {
+	return !existing.ScheduledTime.Equal(updated.ScheduledTime) || existing.DurationMinutes != updated.DurationMinutes
+}
+
+// excludeInterview drops conflicts with the interview being edited.
+func excludeInterview(conflicts []Conflict, id int) []Conflict {
+	var kept []Conflict
+	for _, c := range conflicts {
+		if c.InterviewID != id {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func (s *Server) getReschedules(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	reschedules, err := s.interviews.ListReschedules(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(reschedules)
+}
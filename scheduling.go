@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxInterviewDuration bounds how long a single interview can be. Conflict
+// queries rely on it to limit how far back they look for overlapping
+// interviews.
+const maxInterviewDuration = 24 * time.Hour
+
+// SchedulingConfig holds the rules applied when interviews are booked.
+type SchedulingConfig struct {
+	// DefaultDuration is used when a request gives neither a duration nor an
+	// end time.
+	DefaultDuration time.Duration `yaml:"default_duration"`
+	// Buffer is the minimum gap kept between two interviews of the same
+	// participant.
+	Buffer time.Duration `yaml:"buffer"`
+	// AssignmentStrategy picks interviewers for requests that leave the
+	// choice to the service; see assignmentStrategies.
+	AssignmentStrategy string `yaml:"assignment_strategy"`
+	// DeletePolicy decides what deleting an interviewer, candidate or HR
+	// does to their upcoming interviews: block, cascade or reassign.
+	DeletePolicy string `yaml:"delete_policy"`
+}
+
+func defaultSchedulingConfig() SchedulingConfig {
+	return SchedulingConfig{
+		DefaultDuration:    time.Hour,
+		AssignmentStrategy: "round_robin",
+		DeletePolicy:       DeleteBlock,
+	}
+}
+
+// Duration returns how long the interview lasts.
+func (i Interview) Duration() time.Duration {
+	return time.Duration(i.DurationMinutes) * time.Minute
+}
+
+// normalizeTimes validates the interview's time range, fills in whichever
+// of DurationMinutes and EndTime the caller left out and converts the start
+// time to UTC.
+func (i *Interview) normalizeTimes(defaultDuration time.Duration) error {
+	if i.ScheduledTime.IsZero() {
+		return errors.New("scheduled_time is required")
+	}
+	i.ScheduledTime = i.ScheduledTime.UTC().Truncate(time.Second)
+
+	switch {
+	case i.DurationMinutes == 0 && !i.EndTime.IsZero():
+		length := i.EndTime.Sub(i.ScheduledTime)
+		if length%time.Minute != 0 {
+			return errors.New("end_time must be a whole number of minutes after scheduled_time")
+		}
+		i.DurationMinutes = int(length / time.Minute)
+	case i.DurationMinutes == 0:
+		i.DurationMinutes = int(defaultDuration / time.Minute)
+	case !i.EndTime.IsZero() && !i.EndTime.Equal(i.ScheduledTime.Add(i.Duration())):
+		return errors.New("end_time does not match scheduled_time plus duration_minutes")
+	}
+
+	if i.DurationMinutes <= 0 {
+		return errors.New("the interview must end after it starts")
+	}
+	if i.Duration() > maxInterviewDuration {
+		return fmt.Errorf("interviews cannot be longer than %s", maxInterviewDuration)
+	}
+
+	i.EndTime = i.ScheduledTime.Add(i.Duration())
+	return nil
+}
+
+// Participant roles. Interviewers, candidates and HRs live in separate
+// tables, so an ID only identifies a person together with its role.
+const (
+	RoleInterviewer = "interviewer"
+	RoleCandidate   = "candidate"
+	RoleHR          = "hr"
+)
+
+// Participant is one person taking part in an interview.
+type Participant struct {
+	Role string `json:"role"`
+	ID   int    `json:"id"`
+}
+
+// Conflict reports why one of a requested slot's participants cannot attend:
+// either an existing interview overlaps the slot, or the participant is
+// unavailable, in which case Reason says why and InterviewID is zero.
+type Conflict struct {
+	Role          string    `json:"role"`
+	ParticipantID int       `json:"participant_id"`
+	InterviewID   int       `json:"interview_id,omitempty"`
+	ScheduledTime time.Time `json:"scheduled_time"`
+	EndTime       time.Time `json:"end_time"`
+	Reason        string    `json:"reason,omitempty"`
+}
+
+func (c Conflict) String() string {
+	if c.InterviewID == 0 {
+		return fmt.Sprintf("%s %d is unavailable: %s", roleTitle(c.Role), c.ParticipantID, c.Reason)
+	}
+	return fmt.Sprintf("%s %d has interview %d scheduled from %s to %s",
+		roleTitle(c.Role), c.ParticipantID, c.InterviewID, formatTime(c.ScheduledTime), formatTime(c.EndTime))
+}
+
+func roleTitle(role string) string {
+	switch role {
+	case RoleInterviewer:
+		return "Interviewer"
+	case RoleCandidate:
+		return "Candidate"
+	case RoleHR:
+		return "HR"
+	}
+	return role
+}
+
+// participants lists everyone taking part in the interview.
+func (i Interview) participants() []Participant {
+	var participants []Participant
+	if len(i.Panel) == 0 {
+		participants = append(participants, Participant{RoleInterviewer, i.InterviewerID})
+	}
+	for _, p := range i.Panel {
+		participants = append(participants, Participant{RoleInterviewer, p.InterviewerID})
+	}
+	return append(participants, Participant{RoleHR, i.HRID}, Participant{RoleCandidate, i.CandidateID})
+}
+
+// hasParticipant reports whether p takes part in the interview in p's role.
+func (i Interview) hasParticipant(p Participant) bool {
+	for _, q := range i.participants() {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictsWith returns a Conflict for every participant that the existing
+// interview shares with the requested range [start, end).
+func conflictsWith(existing Interview, participants []Participant, start, end time.Time) []Conflict {
+	if !existing.overlaps(start, end) {
+		return nil
+	}
+	var conflicts []Conflict
+	for _, p := range participants {
+		if existing.hasParticipant(p) {
+			conflicts = append(conflicts, Conflict{
+				Role:          p.Role,
+				ParticipantID: p.ID,
+				InterviewID:   existing.ID,
+				ScheduledTime: existing.ScheduledTime,
+				EndTime:       existing.EndTime,
+			})
+		}
+	}
+	return conflicts
+}
+
+// overlaps reports whether the interview intersects [start, end).
+func (i Interview) overlaps(start, end time.Time) bool {
+	return i.ScheduledTime.Before(end) && i.EndTime.After(start)
+}
+
+// formatTime renders t for notifications.
+func formatTime(t time.Time) string {
+	return t.Format("Mon, 02 Jan 2006 15:04 MST")
+}
+
+// bookingWindow pads the interview's time range with the configured buffer.
+func (s *Server) bookingWindow(interview Interview) (time.Time, time.Time) {
+	return interview.ScheduledTime.Add(-s.scheduling.Buffer), interview.EndTime.Add(s.scheduling.Buffer)
+}
+
+// checkSlot returns the conflicts that prevent participants from attending
+// interview: overlapping interviews, widened by the configured buffer, and
+// unavailability. The interview being rescheduled, if any, is given by
+// exclude and ignored.
+func (s *Server) checkSlot(ctx context.Context, tx Store, participants []Participant, interview Interview, exclude int) ([]Conflict, error) {
+	start, end := s.bookingWindow(interview)
+	conflicts, err := tx.FindConflicts(ctx, participants, start, end)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = excludeInterview(conflicts, exclude)
+
+	unavailable, err := unavailability(ctx, tx, participants, interview.ScheduledTime, interview.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	return append(conflicts, unavailable...), nil
+}
+
+// book saves a new interview unless one of its participants is already busy
+// or unavailable during it. The check and the insert happen atomically, so concurrent
+// requests cannot double-book anyone. On success interview.ID is set.
+func (s *Server) book(ctx context.Context, interview *Interview) ([]Conflict, error) {
+	return s.bookWith(ctx, interview, nil)
+}
+
+// bookWith is book with an extra step, then, run in the same atomic unit
+// after the interview is saved. It is skipped when nil or when there are
+// conflicts.
+func (s *Server) bookWith(ctx context.Context, interview *Interview, then func(tx Store) error) ([]Conflict, error) {
+	var conflicts []Conflict
+	err := s.tx.Atomically(ctx, interview.participants(), func(tx Store) error {
+		var err error
+		conflicts, err = s.checkSlot(ctx, tx, interview.participants(), *interview, 0)
+		if err != nil || len(conflicts) > 0 {
+			return err
+		}
+		interview.Status = StatusScheduled
+		interview.ID, err = tx.CreateInterview(ctx, *interview)
+		if err != nil || then == nil {
+			return err
+		}
+		return then(tx)
+	})
+	return conflicts, err
+}
+
+// atomicallyOnInterview runs fn in an atomic unit that locks the stored
+// participants of interview id along with participants, and passes fn the
+// interview as stored. Changes to the same interview share at least its
+// lead, so they are serialised and none is lost to another that read the
+// interview before it was written. Should the interview gain a participant
+// between the read and the lock, it starts over with the new ones.
+func (s *Server) atomicallyOnInterview(ctx context.Context, id int, participants []Participant, fn func(tx Store, existing Interview) error) error {
+	for {
+		stored, err := s.interviews.GetInterview(ctx, id)
+		if err != nil {
+			return err
+		}
+		locked := append(stored.participants(), participants...)
+		isLocked := map[Participant]bool{}
+		for _, p := range locked {
+			isLocked[p] = true
+		}
+
+		retry := false
+		err = s.tx.Atomically(ctx, locked, func(tx Store) error {
+			existing, err := tx.GetInterview(ctx, id)
+			if err != nil {
+				return err
+			}
+			for _, p := range existing.participants() {
+				if !isLocked[p] {
+					retry = true
+					return nil
+				}
+			}
+			return fn(tx, existing)
+		})
+		if err != nil || !retry {
+			return err
+		}
+	}
+}
+
+// reschedule replaces interview id with interview after the same conflict
+// checks as book, ignoring the interview's own current slot. When the time
+// changes the interview is flagged as rescheduled and the change is added to
+// its history with reason and actor. It returns the interview as it was
+// before the update.
+func (s *Server) reschedule(ctx context.Context, id int, interview *Interview, reason, actor string) (Interview, []Conflict, error) {
+	return s.rescheduleWith(ctx, id, interview, reason, actor, nil)
+}
+
+// rescheduleWith is reschedule with a precondition, check, run in the same
+// atomic unit against the interview as currently stored. When it fails the
+// interview is left unchanged. A nil check always passes. Only scheduled
+// and confirmed interviews can be rescheduled; the status is kept.
+func (s *Server) rescheduleWith(ctx context.Context, id int, interview *Interview, reason, actor string, check func(tx Store, existing Interview) error) (Interview, []Conflict, error) {
+	var existing Interview
+	var conflicts []Conflict
+	err := s.atomicallyOnInterview(ctx, id, interview.participants(), func(tx Store, stored Interview) error {
+		existing = stored
+		if check != nil {
+			if err := check(tx, existing); err != nil {
+				return err
+			}
+		}
+		if !existing.open() {
+			return fmt.Errorf("%w: a %s interview cannot be changed", ErrInvalidTransition, existing.Status)
+		}
+		if timeChanged(existing, *interview) {
+			if err := s.checkLoopReschedule(ctx, tx, existing, *interview); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		conflicts, err = s.checkSlot(ctx, tx, interview.participants(), *interview, id)
+		if err != nil || len(conflicts) > 0 {
+			return err
+		}
+
+		// Once moved, an interview stays marked as rescheduled.
+		rescheduled := timeChanged(existing, *interview)
+		if rescheduled || existing.Rescheduled {
+			interview.Rescheduled = true
+		}
+		interview.ID = id
+		interview.Status = existing.Status
+
+		err = tx.UpdateInterview(ctx, id, *interview)
+		if err != nil || !rescheduled {
+			return err
+		}
+		return tx.AddReschedule(ctx, Reschedule{
+			InterviewID:             id,
+			PreviousTime:            existing.ScheduledTime,
+			PreviousDurationMinutes: existing.DurationMinutes,
+			NewTime:                 interview.ScheduledTime,
+			NewDurationMinutes:      interview.DurationMinutes,
+			Reason:                  reason,
+			Actor:                   actor,
+			CreatedAt:               time.Now().UTC().Truncate(time.Second),
+		})
+	})
+	return existing, conflicts, err
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServer returns a server on store with the default configuration
+// whose notifications are recorded instead of published.
+func newTestServer(store Store) (*Server, *[]Notification) {
+	s := NewServer(store, defaultSchedulingConfig(), defaultBookingConfig(), AdminConfig{}, Notifiers{ChannelSMS: smsNotifier{}})
+	var mu sync.Mutex
+	var sent []Notification
+	s.publish = func(ctx context.Context, n Notification) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, n)
+		return nil
+	}
+	return s, &sent
+}
+
+func TestConcurrentBookings(t *testing.T) {
+	const requests = 20
+	body := `{"interviewer_id": 1, "candidate_id": 1, "hr_id": 1, "scheduled_time": "2030-03-04T10:00:00Z", "duration_minutes": 60}`
+
+	for _, backend := range storeBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.open(t)
+			seedPeople(t, ctx, store)
+			s, _ := newTestServer(store)
+			router := s.routes()
+
+			// Every request waits on start so they all race for the slot.
+			start := make(chan struct{})
+			codes := make(chan int, requests)
+			var wg sync.WaitGroup
+			for i := 0; i < requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/interview", strings.NewReader(body)))
+					if w.Code != http.StatusOK && w.Code != http.StatusConflict {
+						t.Errorf("unexpected response %d: %s", w.Code, w.Body)
+					}
+					codes <- w.Code
+				}()
+			}
+			close(start)
+			wg.Wait()
+			close(codes)
+
+			counts := map[int]int{}
+			for code := range codes {
+				counts[code]++
+			}
+			if counts[http.StatusOK] != 1 || counts[http.StatusConflict] != requests-1 {
+				t.Errorf("got %d created and %d conflicts, want 1 and %d", counts[http.StatusOK], counts[http.StatusConflict], requests-1)
+			}
+
+			interviews, err := store.ListInterviews(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(interviews) != 1 {
+				t.Errorf("got %d interviews stored, want 1", len(interviews))
+			}
+		})
+	}
+}
+
+func TestConcurrentReschedules(t *testing.T) {
+	const requests = 10
+
+	for _, backend := range storeBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.open(t)
+			seedPeople(t, ctx, store)
+			id, err := store.CreateInterview(ctx, testInterview(testTime))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, _ := newTestServer(store)
+			router := s.routes()
+
+			start := make(chan struct{})
+			var wg sync.WaitGroup
+			for i := 1; i <= requests; i++ {
+				body := fmt.Sprintf(`{"interviewer_id": 1, "candidate_id": 1, "hr_id": 1, "scheduled_time": %q, "duration_minutes": 60, "reason": "move %d"}`,
+					testTime.Add(time.Duration(i)*24*time.Hour).Format(time.RFC3339), i)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, fmt.Sprintf("/interview/%d", id), strings.NewReader(body)))
+					if w.Code != http.StatusOK {
+						t.Errorf("unexpected response %d: %s", w.Code, w.Body)
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			// Serialised reschedules form one chain: each starts where the
+			// one before it ended.
+			reschedules, err := store.ListReschedules(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reschedules) != requests {
+				t.Fatalf("got %d reschedules, want %d", len(reschedules), requests)
+			}
+			previous := testTime
+			for _, r := range reschedules {
+				if !r.PreviousTime.Equal(previous) {
+					t.Errorf("reschedule %d moved the interview from %s, but it was at %s", r.ID, r.PreviousTime, previous)
+				}
+				previous = r.NewTime
+			}
+			interview, err := store.GetInterview(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !interview.ScheduledTime.Equal(previous) || interview.Sequence != requests {
+				t.Errorf("got the interview at %s with sequence %d, want %s and %d", interview.ScheduledTime, interview.Sequence, previous, requests)
+			}
+		})
+	}
+}
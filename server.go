@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Server exposes the interview scheduling API over HTTP.
+type Server struct {
+	interviewers InterviewerStore
+	candidates   CandidateStore
+	hrs          HRStore
+	interviews   InterviewStore
+	availability AvailabilityStore
+	assignments  AssignmentStore
+	loops        LoopStore
+	bookings     BookingLinkStore
+	tx           Transactor
+	scheduling   SchedulingConfig
+	booking      BookingConfig
+	admin        AdminConfig
+	notifiers    Notifiers
+	// publish queues notifications for delivery; publishNotification
+	// outside tests.
+	publish func(ctx context.Context, n Notification) error
+}
+
+func NewServer(store Store, scheduling SchedulingConfig, booking BookingConfig, admin AdminConfig, notifiers Notifiers) *Server {
+	return &Server{
+		interviewers: store,
+		candidates:   store,
+		hrs:          store,
+		interviews:   store,
+		availability: store,
+		assignments:  store,
+		loops:        store,
+		bookings:     store,
+		tx:           store,
+		scheduling:   scheduling,
+		booking:      booking,
+		admin:        admin,
+		notifiers:    notifiers,
+		publish:      publishNotification,
+	}
+}
+
+func (s *Server) routes() *mux.Router {
+	router := mux.NewRouter()
+
+	router.HandleFunc("/interviewer/{id}", s.getInterviewer).Methods("GET")
+	router.HandleFunc("/interviewer", s.createInterviewer).Methods("POST")
+	router.HandleFunc("/interviewer/{id}", s.updateInterviewer).Methods("PUT")
+	router.HandleFunc("/interviewer/{id}", s.deleteInterviewer).Methods("DELETE")
+	router.HandleFunc("/interviewers", s.GetAllInterviewers).Methods("GET")
+	router.HandleFunc("/interviewer/{id}/skills", s.getInterviewerSkills).Methods("GET")
+	router.HandleFunc("/interviewer/{id}/skills", s.setInterviewerSkills).Methods("PUT")
+
+	router.HandleFunc("/candidate", s.createCandidate).Methods("POST")
+	router.HandleFunc("/candidates", s.getAllCandidates).Methods("GET")
+	router.HandleFunc("/candidate/{id}", s.getCandidate).Methods("GET")
+	router.HandleFunc("/candidate/{id}", s.updateCandidate).Methods("PUT")
+	router.HandleFunc("/candidate/{id}", s.deleteCandidate).Methods("DELETE")
+	router.HandleFunc("/candidate/{id}/loops", s.getCandidateLoops).Methods("GET")
+	router.HandleFunc("/candidate/{id}/booking-links", s.createBookingLink).Methods("POST")
+
+	router.HandleFunc("/hr/{id}", s.getHR).Methods("GET")
+	router.HandleFunc("/hr", s.createHR).Methods("POST")
+	router.HandleFunc("/hr/{id}", s.updateHR).Methods("PUT")
+	router.HandleFunc("/hr/{id}", s.deleteHR).Methods("DELETE")
+	router.HandleFunc("/hrs", s.GetAllHRs).Methods("GET")
+
+	// Registered before /interview/{id}, which would otherwise match it.
+	router.HandleFunc("/interview/suggestions", s.getSuggestions).Methods("GET")
+	router.HandleFunc("/interview/{id}", s.getInterview).Methods("GET")
+	router.HandleFunc("/interview", s.createInterview).Methods("POST")
+	router.HandleFunc("/interview/{id}", s.UpdateInterview).Methods("PUT")
+	router.HandleFunc("/interview/{id}", s.DeleteInterview).Methods("DELETE")
+	router.HandleFunc("/interview/{id}/reschedules", s.getReschedules).Methods("GET")
+	router.HandleFunc("/interview/{id}/assignment", s.getAssignment).Methods("GET")
+	router.HandleFunc("/interview/{id}/panelists", s.createPanelist).Methods("POST")
+	router.HandleFunc("/interview/{id}/panelists/{interviewerId}", s.deletePanelist).Methods("DELETE")
+	router.HandleFunc("/interview/{id}/{action:confirm|start|complete|cancel|no-show}", s.updateInterviewStatus).Methods("POST")
+	router.HandleFunc("/interview/{id}/status-history", s.getStatusHistory).Methods("GET")
+	router.HandleFunc("/interview/{id}/ics", s.getInterviewCalendar).Methods("GET")
+	router.HandleFunc("/interviews", s.GetAllInterviews).Methods("GET")
+	router.HandleFunc("/interviews/bulk", s.createInterviewsBulk).Methods("POST")
+
+	router.HandleFunc("/loop", s.createLoop).Methods("POST")
+	router.HandleFunc("/loop/{id}", s.getLoop).Methods("GET")
+
+	// Public: candidates reach these through the links they are sent.
+	router.HandleFunc("/booking/{token}", s.getBookingLink).Methods("GET")
+	router.HandleFunc("/booking/{token}", s.bookSlot).Methods("POST")
+	router.HandleFunc("/manage/{token}", s.getManageLink).Methods("GET")
+	router.HandleFunc("/manage/{token}/reschedule", s.rescheduleFromLink).Methods("POST")
+	router.HandleFunc("/manage/{token}/cancel", s.cancelFromLink).Methods("POST")
+
+	router.HandleFunc("/admin/interview/{id}", s.requireAdmin(s.purgeInterview)).Methods("DELETE")
+
+	router.HandleFunc("/{role:interviewer|hr}/{id}/availability", s.getAvailability).Methods("GET")
+	router.HandleFunc("/{role:interviewer|hr}/{id}/working-hours", s.createWorkingHours).Methods("POST")
+	router.HandleFunc("/{role:interviewer|hr}/{id}/working-hours/{entry}", s.updateWorkingHours).Methods("PUT")
+	router.HandleFunc("/{role:interviewer|hr}/{id}/working-hours/{entry}", s.deleteWorkingHours).Methods("DELETE")
+	router.HandleFunc("/{role:interviewer|hr}/{id}/time-off", s.createTimeOff).Methods("POST")
+	router.HandleFunc("/{role:interviewer|hr}/{id}/time-off/{entry}", s.updateTimeOff).Methods("PUT")
+	router.HandleFunc("/{role:interviewer|hr}/{id}/time-off/{entry}", s.deleteTimeOff).Methods("DELETE")
+
+	return router
+}
+
+// pathID parses the {id} route variable, writing a 400 response when it is
+// not a number.
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid id: "+mux.Vars(r)["id"], http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// sendConflicts rejects a booking with 409 Conflict, listing which
+// participant clashes with which existing interview.
+func sendConflicts(w http.ResponseWriter, conflicts []Conflict) {
+	messages := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		messages[i] = c.String()
+	}
+	sendConflictsMessage(w, strings.Join(messages, "; "), conflicts)
+}
+
+// sendConflictsMessage is sendConflicts with a custom message.
+func sendConflictsMessage(w http.ResponseWriter, message string, conflicts []Conflict) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]any{
+		"message":   message,
+		"conflicts": conflicts,
+	})
+}
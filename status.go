@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Interview statuses. New interviews are scheduled; completed, cancelled
+// and no_show are final.
+const (
+	StatusScheduled  = "scheduled"
+	StatusConfirmed  = "confirmed"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+	StatusNoShow     = "no_show"
+)
+
+// ErrInvalidTransition is returned when an interview cannot move to the
+// requested status from the one it is in.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// statusTransitions lists the statuses each status can move to.
+var statusTransitions = map[string][]string{
+	StatusScheduled:  {StatusConfirmed, StatusInProgress, StatusCompleted, StatusCancelled, StatusNoShow},
+	StatusConfirmed:  {StatusInProgress, StatusCompleted, StatusCancelled, StatusNoShow},
+	StatusInProgress: {StatusCompleted},
+	StatusCompleted:  nil,
+	StatusCancelled:  nil,
+	StatusNoShow:     nil,
+}
+
+// statusActions maps the action in POST /interview/{id}/{action} to the
+// status it moves the interview to.
+var statusActions = map[string]string{
+	"confirm":  StatusConfirmed,
+	"start":    StatusInProgress,
+	"complete": StatusCompleted,
+	"cancel":   StatusCancelled,
+	"no-show":  StatusNoShow,
+}
+
+// StatusChange records one status transition of an interview.
+type StatusChange struct {
+	ID          int       `json:"id"`
+	InterviewID int       `json:"interview_id"`
+	FromStatus  string    `json:"from_status"`
+	ToStatus    string    `json:"to_status"`
+	Reason      string    `json:"reason"`
+	Actor       string    `json:"actor"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// initialStatus is the status the interview is stored with: its own, or
+// scheduled for a new interview that has none.
+func (i Interview) initialStatus() string {
+	if i.Status == "" {
+		return StatusScheduled
+	}
+	return i.Status
+}
+
+// open reports whether the interview is still ahead, that is scheduled or
+// confirmed, and so can be rescheduled or cancelled.
+func (i Interview) open() bool {
+	return i.Status == StatusScheduled || i.Status == StatusConfirmed
+}
+
+// canTransition reports whether an interview can move from one status to
+// another.
+func canTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition returns why interview cannot move to status at now, or
+// nil when it can. Interviews cannot be completed or marked as a no-show
+// before they start.
+func checkTransition(interview Interview, status string, now time.Time) error {
+	if !canTransition(interview.Status, status) {
+		return fmt.Errorf("%w: a %s interview cannot become %s", ErrInvalidTransition, interview.Status, status)
+	}
+	if (status == StatusCompleted || status == StatusNoShow) && now.Before(interview.ScheduledTime) {
+		return fmt.Errorf("%w: the interview has not started yet", ErrInvalidTransition)
+	}
+	return nil
+}
+
+// parseStatuses splits a comma-separated list of statuses, rejecting
+// unknown ones.
+func parseStatuses(text string) ([]string, error) {
+	if text == "" {
+		return nil, nil
+	}
+	var statuses []string
+	for _, status := range strings.Split(text, ",") {
+		status = strings.TrimSpace(status)
+		if _, ok := statusTransitions[status]; !ok {
+			return nil, fmt.Errorf("status %q is not one of scheduled, confirmed, in_progress, completed, cancelled, no_show", status)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// changeStatus moves interview id to status, recording reason and actor in
+// its status history, and returns the interview as it was before. check,
+// when not nil, is an extra precondition run in the same atomic unit
+// against the stored interview.
+func (s *Server) changeStatus(ctx context.Context, id int, status, reason, actor string, check func(existing Interview) error) (Interview, error) {
+	existing, err := s.interviews.GetInterview(ctx, id)
+	if err != nil {
+		return existing, err
+	}
+
+	now := time.Now().UTC()
+	err = s.tx.Atomically(ctx, existing.participants(), func(tx Store) error {
+		var err error
+		existing, err = tx.GetInterview(ctx, id)
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(existing); err != nil {
+				return err
+			}
+		}
+		if err := checkTransition(existing, status, now); err != nil {
+			return err
+		}
+		return tx.SetInterviewStatus(ctx, StatusChange{
+			InterviewID: id,
+			FromStatus:  existing.Status,
+			ToStatus:    status,
+			Reason:      reason,
+			Actor:       actor,
+			CreatedAt:   now.Truncate(time.Second),
+		})
+	})
+	return existing, err
+}
+
+// notifyCancelled tells the panel, the HR and the candidate that interview
+// has been cancelled. The reason is shared with the panel and the HR only.
+// Participants who have since been deleted are skipped.
+func (s *Server) notifyCancelled(ctx context.Context, interview Interview, reason string) error {
+	message := func(zone string) string {
+		return "Your interview scheduled at " + localTime(interview.ScheduledTime, zone) + " has been cancelled"
+	}
+	withReason := func(zone string) string {
+		if reason == "" {
+			return message(zone)
+		}
+		return message(zone) + ". Reason: " + reason
+	}
+
+	metadata := interviewMetadata(EventCancelled, interview)
+	for _, p := range interview.Panel {
+		err := s.notifyInterviewer(ctx, p.InterviewerID, metadata, withReason)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	hr, err := s.hrs.GetHR(ctx, interview.HRID)
+	if err == nil {
+		err = s.notify(ctx, hr.recipient(), withReason(hr.TimeZone), metadata)
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	candidate, err := s.candidates.GetCandidate(ctx, interview.CandidateID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.notify(ctx, candidate.recipient(), message(candidate.TimeZone), metadata)
+}
+
+// statusRequest is the optional body of POST /interview/{id}/{action} and
+// DELETE /interview/{id}.
+type statusRequest struct {
+	Reason string `json:"reason"`
+	Actor  string `json:"actor"`
+}
+
+func (s *Server) updateInterviewStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	status := statusActions[mux.Vars(r)["action"]]
+
+	var req statusRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interview, err := s.changeStatus(r.Context(), id, status, req.Reason, req.Actor, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+		} else if errors.Is(err, ErrInvalidTransition) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if status == StatusCancelled {
+		err = s.notifyCancelled(r.Context(), interview, req.Reason)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	SendResponse(map[string]string{"message": "Interview Status Updated Successfully", "status": status}, w)
+}
+
+func (s *Server) getStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	changes, err := s.interviews.ListStatusChanges(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(changes)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"example.com/database"
+)
+
+var (
+	// ErrNotFound is returned by stores when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a record would violate a uniqueness rule.
+	ErrDuplicate = errors.New("duplicate record")
+	// ErrUnknownParticipant is returned by Atomically when a participant does
+	// not exist.
+	ErrUnknownParticipant = errors.New("unknown participant")
+)
+
+type InterviewerStore interface {
+	CreateInterviewer(ctx context.Context, interviewer Interviewer) error
+	GetInterviewer(ctx context.Context, id int) (Interviewer, error)
+	GetInterviewerByPhoneNumber(ctx context.Context, phoneNumber string) (Interviewer, error)
+	ListInterviewers(ctx context.Context) ([]Interviewer, error)
+	UpdateInterviewer(ctx context.Context, interviewer Interviewer) error
+	DeleteInterviewer(ctx context.Context, id int) error
+}
+
+type CandidateStore interface {
+	CreateCandidate(ctx context.Context, candidate Candidate) error
+	GetCandidate(ctx context.Context, id int) (Candidate, error)
+	GetCandidateByPhoneNumber(ctx context.Context, phoneNumber string) (Candidate, error)
+	ListCandidates(ctx context.Context) ([]Candidate, error)
+	UpdateCandidate(ctx context.Context, candidate Candidate) error
+	DeleteCandidate(ctx context.Context, id int) error
+}
+
+type HRStore interface {
+	CreateHR(ctx context.Context, hr HR) error
+	GetHR(ctx context.Context, id int) (HR, error)
+	GetHRByPhoneNumber(ctx context.Context, phoneNumber string) (HR, error)
+	ListHRs(ctx context.Context) ([]HR, error)
+	UpdateHR(ctx context.Context, hr HR) error
+	DeleteHR(ctx context.Context, id int) error
+}
+
+type InterviewStore interface {
+	// CreateInterview inserts the interview and returns its id.
+	CreateInterview(ctx context.Context, interview Interview) (int, error)
+	GetInterview(ctx context.Context, id int) (Interview, error)
+	// ListInterviews returns the interviews ordered by id, only those in one
+	// of statuses when any are given.
+	ListInterviews(ctx context.Context, statuses ...string) ([]Interview, error)
+	UpdateInterview(ctx context.Context, id int, interview Interview) error
+	// DeleteInterview removes the interview together with its panel and its
+	// reschedule, status, assignment and booking history. It also takes the
+	// interview's round out of its loop, leaving the other rounds at their
+	// positions, and removes a loop left without rounds. Interviews are
+	// normally cancelled instead; deleting is for purging.
+	DeleteInterview(ctx context.Context, id int) error
+	// FindConflicts returns the existing interviews overlapping [start, end)
+	// that any of participants takes part in, in the same role. Cancelled
+	// interviews are ignored.
+	FindConflicts(ctx context.Context, participants []Participant, start, end time.Time) ([]Conflict, error)
+	AddReschedule(ctx context.Context, reschedule Reschedule) error
+	ListReschedules(ctx context.Context, interviewID int) ([]Reschedule, error)
+	// SetInterviewStatus moves the interview to change.ToStatus and records
+	// the change in its status history.
+	SetInterviewStatus(ctx context.Context, change StatusChange) error
+	ListStatusChanges(ctx context.Context, interviewID int) ([]StatusChange, error)
+}
+
+// AvailabilityStore keeps the working hours and time off of interviewers and
+// HRs. Updates and deletes fail with ErrNotFound when the entry does not
+// exist or belongs to someone else.
+type AvailabilityStore interface {
+	// CreateWorkingHours inserts the entry and returns its id.
+	CreateWorkingHours(ctx context.Context, wh WorkingHours) (int, error)
+	ListWorkingHours(ctx context.Context, owner Participant) ([]WorkingHours, error)
+	UpdateWorkingHours(ctx context.Context, wh WorkingHours) error
+	DeleteWorkingHours(ctx context.Context, owner Participant, id int) error
+	// CreateTimeOff inserts the entry and returns its id.
+	CreateTimeOff(ctx context.Context, off TimeOff) (int, error)
+	// ListTimeOff returns owner's time off overlapping [from, to), ordered by
+	// start time. A zero from or to leaves that side of the range open.
+	ListTimeOff(ctx context.Context, owner Participant, from, to time.Time) ([]TimeOff, error)
+	UpdateTimeOff(ctx context.Context, off TimeOff) error
+	DeleteTimeOff(ctx context.Context, owner Participant, id int) error
+}
+
+// AssignmentStore keeps interviewer skills and the record of automatic
+// interviewer assignments.
+type AssignmentStore interface {
+	// SetInterviewerSkills replaces the interviewer's skills.
+	SetInterviewerSkills(ctx context.Context, interviewerID int, skills []string) error
+	GetInterviewerSkills(ctx context.Context, interviewerID int) ([]string, error)
+	// FindInterviewersWithSkills returns the interviewers having every one
+	// of skills, ordered by id. With no skills it returns every interviewer.
+	FindInterviewersWithSkills(ctx context.Context, skills []string) ([]Interviewer, error)
+	AddAssignment(ctx context.Context, assignment Assignment) error
+	GetAssignment(ctx context.Context, interviewID int) (Assignment, error)
+	// LastAssignment returns the most recent assignment made with strategy,
+	// or ErrNotFound when there is none.
+	LastAssignment(ctx context.Context, strategy string) (Assignment, error)
+}
+
+// LoopStore keeps interview loops. The interviews of the rounds are stored
+// through InterviewStore.
+type LoopStore interface {
+	// CreateLoop inserts the loop and its rounds and returns its id.
+	CreateLoop(ctx context.Context, loop Loop) (int, error)
+	GetLoop(ctx context.Context, id int) (Loop, error)
+	// ListLoops returns the candidate's loops, ordered by id.
+	ListLoops(ctx context.Context, candidateID int) ([]Loop, error)
+}
+
+// BookingLinkStore records which booking links have been used. The links
+// themselves are signed tokens and are not stored.
+type BookingLinkStore interface {
+	// RedeemBookingLink records that the link was used to book an interview.
+	// It fails with ErrDuplicate when the link has already been used.
+	RedeemBookingLink(ctx context.Context, redemption BookingRedemption) error
+	GetBookingRedemption(ctx context.Context, linkID string) (BookingRedemption, error)
+}
+
+// Transactor groups store operations into atomic units.
+type Transactor interface {
+	// Atomically runs fn against a view of the store whose changes are
+	// applied together, or not at all when fn returns an error. Calls that
+	// share a participant are serialised, so a conflict check made inside fn
+	// still holds when fn writes the booking. It fails with
+	// ErrUnknownParticipant when a participant does not exist.
+	Atomically(ctx context.Context, participants []Participant, fn func(Store) error) error
+}
+
+// Store bundles the persistence the HTTP server depends on.
+type Store interface {
+	InterviewerStore
+	CandidateStore
+	HRStore
+	InterviewStore
+	AvailabilityStore
+	AssignmentStore
+	LoopStore
+	BookingLinkStore
+	Transactor
+}
+
+// sortedParticipants returns participants in a fixed order, without
+// duplicates, so that locks are always taken in the same sequence.
+func sortedParticipants(participants []Participant) []Participant {
+	sorted := make([]Participant, 0, len(participants))
+	seen := map[Participant]bool{}
+	for _, p := range participants {
+		if !seen[p] {
+			seen[p] = true
+			sorted = append(sorted, p)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Role != sorted[j].Role {
+			return sorted[i].Role < sorted[j].Role
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// openStore creates the Store backend selected by cfg.Store, applying
+// pending migrations first when database.auto_migrate is set. The returned
+// function releases any resources held by the store.
+func openStore(cfg Config) (Store, func() error, error) {
+	if cfg.Store == "memory" {
+		return newMemoryStore(), func() error { return nil }, nil
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Database.AutoMigrate {
+		applied, err := database.MigrateUp(context.Background(), db, cfg.Store)
+		if err != nil {
+			database.Close()
+			return nil, nil, err
+		}
+		for _, m := range applied {
+			log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+		}
+	}
+
+	return newSQLStore(db, cfg.Store), database.Close, nil
+}
+
+// openDB connects the shared database pool behind the mysql and sqlite
+// stores.
+func openDB(cfg Config) (*sql.DB, error) {
+	if cfg.Store == "memory" {
+		return nil, fmt.Errorf("the %s store has no database", cfg.Store)
+	}
+	return database.Connect(cfg.Store, cfg.Database)
+}
@@ -0,0 +1,888 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore is a Store kept entirely in process memory. It mirrors the
+// MySQL store's behaviour, including phone number and primary key
+// uniqueness, and is meant for tests and local development.
+type memoryStore struct {
+	// bookingMu serialises Atomically calls; mu guards the maps.
+	bookingMu    sync.Mutex
+	mu           sync.RWMutex
+	interviewers map[int]Interviewer
+	candidates   map[int]Candidate
+	hrs          map[int]HR
+	interviews   map[int]Interview
+	reschedules  map[int]Reschedule
+	statuses     map[int]StatusChange
+	workingHours map[int]WorkingHours
+	timeOff      map[int]TimeOff
+	skills       map[int][]string
+	assignments  map[int]Assignment
+	loops        map[int]Loop
+	redemptions  map[string]BookingRedemption
+}
+
+// Atomically serialises fn with every other Atomically call. Like the SQL
+// stores it applies fn's writes together or not at all: when fn fails, the
+// writes it made through its Store are undone.
+func (s *memoryStore) Atomically(ctx context.Context, participants []Participant, fn func(Store) error) error {
+	s.bookingMu.Lock()
+	defer s.bookingMu.Unlock()
+
+	if err := s.checkParticipants(participants); err != nil {
+		return err
+	}
+	tx := memoryTx{s, new([]func())}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
+
+// memoryTx is the view of a memoryStore passed to Atomically callbacks. The
+// writes callbacks make record how to undo themselves; the others, such as
+// DeleteInterview, cannot be rolled back. Nested Atomically calls run
+// inline instead of deadlocking on bookingMu and are undone with the
+// outermost call.
+type memoryTx struct {
+	*memoryStore
+	// undo holds the undo steps in the order the writes were made. They run
+	// with mu held.
+	undo *[]func()
+}
+
+func (tx memoryTx) Atomically(ctx context.Context, participants []Participant, fn func(Store) error) error {
+	if err := tx.checkParticipants(participants); err != nil {
+		return err
+	}
+	return fn(tx)
+}
+
+// rollback undoes every write made through tx, latest first.
+func (tx memoryTx) rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	for i := len(*tx.undo) - 1; i >= 0; i-- {
+		(*tx.undo)[i]()
+	}
+	*tx.undo = nil
+}
+
+// saveRecord records how to put records[key] back as it is now, or remove
+// it when it does not exist yet.
+func saveRecord[K comparable, T any](tx memoryTx, records map[K]T, key K) {
+	tx.mu.RLock()
+	record, ok := records[key]
+	tx.mu.RUnlock()
+
+	*tx.undo = append(*tx.undo, func() {
+		if ok {
+			records[key] = record
+		} else {
+			delete(records, key)
+		}
+	})
+}
+
+// newRecordID returns id, or when it is zero the id the next record added to
+// records will get. Interviews and their history are only added inside
+// Atomically, so nothing else can take the id in between.
+func newRecordID[T any](tx memoryTx, records map[int]T, id int) int {
+	tx.mu.RLock()
+	defer tx.mu.RUnlock()
+
+	if id != 0 {
+		return id
+	}
+	id, _ = nextID(records, 0)
+	return id
+}
+
+func (tx memoryTx) DeleteInterviewer(ctx context.Context, id int) error {
+	saveRecord(tx, tx.interviewers, id)
+	return tx.memoryStore.DeleteInterviewer(ctx, id)
+}
+
+func (tx memoryTx) DeleteCandidate(ctx context.Context, id int) error {
+	saveRecord(tx, tx.candidates, id)
+	return tx.memoryStore.DeleteCandidate(ctx, id)
+}
+
+func (tx memoryTx) DeleteHR(ctx context.Context, id int) error {
+	saveRecord(tx, tx.hrs, id)
+	return tx.memoryStore.DeleteHR(ctx, id)
+}
+
+func (tx memoryTx) CreateInterview(ctx context.Context, interview Interview) (int, error) {
+	interview.ID = newRecordID(tx, tx.interviews, interview.ID)
+	saveRecord(tx, tx.interviews, interview.ID)
+	return tx.memoryStore.CreateInterview(ctx, interview)
+}
+
+func (tx memoryTx) UpdateInterview(ctx context.Context, id int, interview Interview) error {
+	saveRecord(tx, tx.interviews, id)
+	return tx.memoryStore.UpdateInterview(ctx, id, interview)
+}
+
+func (tx memoryTx) AddReschedule(ctx context.Context, reschedule Reschedule) error {
+	reschedule.ID = newRecordID(tx, tx.reschedules, reschedule.ID)
+	saveRecord(tx, tx.reschedules, reschedule.ID)
+	return tx.memoryStore.AddReschedule(ctx, reschedule)
+}
+
+func (tx memoryTx) SetInterviewStatus(ctx context.Context, change StatusChange) error {
+	change.ID = newRecordID(tx, tx.statuses, change.ID)
+	saveRecord(tx, tx.statuses, change.ID)
+	saveRecord(tx, tx.interviews, change.InterviewID)
+	return tx.memoryStore.SetInterviewStatus(ctx, change)
+}
+
+func (tx memoryTx) AddAssignment(ctx context.Context, assignment Assignment) error {
+	saveRecord(tx, tx.assignments, assignment.InterviewID)
+	return tx.memoryStore.AddAssignment(ctx, assignment)
+}
+
+func (tx memoryTx) CreateLoop(ctx context.Context, loop Loop) (int, error) {
+	loop.ID = newRecordID(tx, tx.loops, loop.ID)
+	saveRecord(tx, tx.loops, loop.ID)
+	return tx.memoryStore.CreateLoop(ctx, loop)
+}
+
+func (tx memoryTx) RedeemBookingLink(ctx context.Context, redemption BookingRedemption) error {
+	saveRecord(tx, tx.redemptions, redemption.LinkID)
+	return tx.memoryStore.RedeemBookingLink(ctx, redemption)
+}
+
+func (s *memoryStore) checkParticipants(participants []Participant) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range participants {
+		var ok bool
+		switch p.Role {
+		case RoleInterviewer:
+			_, ok = s.interviewers[p.ID]
+		case RoleCandidate:
+			_, ok = s.candidates[p.ID]
+		case RoleHR:
+			_, ok = s.hrs[p.ID]
+		default:
+			return fmt.Errorf("unknown participant role %q", p.Role)
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s %d does not exist", ErrUnknownParticipant, roleTitle(p.Role), p.ID)
+		}
+	}
+	return nil
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		interviewers: map[int]Interviewer{},
+		candidates:   map[int]Candidate{},
+		hrs:          map[int]HR{},
+		interviews:   map[int]Interview{},
+		reschedules:  map[int]Reschedule{},
+		statuses:     map[int]StatusChange{},
+		workingHours: map[int]WorkingHours{},
+		timeOff:      map[int]TimeOff{},
+		skills:       map[int][]string{},
+		assignments:  map[int]Assignment{},
+		loops:        map[int]Loop{},
+		redemptions:  map[string]BookingRedemption{},
+	}
+}
+
+// nextID picks the id for a new record the way an AUTO_INCREMENT column
+// would: a zero id is replaced by one past the largest id in use.
+func nextID[T any](records map[int]T, id int) (int, error) {
+	if id != 0 {
+		if _, ok := records[id]; ok {
+			return 0, fmt.Errorf("%w: id %d", ErrDuplicate, id)
+		}
+		return id, nil
+	}
+	for existing := range records {
+		if existing > id {
+			id = existing
+		}
+	}
+	return id + 1, nil
+}
+
+// sortedValues returns the records ordered by id, or nil when there are none.
+func sortedValues[T any](records map[int]T) []T {
+	ids := make([]int, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var values []T
+	for _, id := range ids {
+		values = append(values, records[id])
+	}
+	return values
+}
+
+func (s *memoryStore) CreateInterviewer(ctx context.Context, interviewer Interviewer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkInterviewerPhone(interviewer); err != nil {
+		return err
+	}
+	id, err := nextID(s.interviewers, interviewer.ID)
+	if err != nil {
+		return err
+	}
+	interviewer.ID = id
+	s.interviewers[id] = interviewer
+	return nil
+}
+
+func (s *memoryStore) checkInterviewerPhone(interviewer Interviewer) error {
+	for _, existing := range s.interviewers {
+		if existing.PhoneNumber == interviewer.PhoneNumber && existing.ID != interviewer.ID {
+			return fmt.Errorf("%w: phone number %s", ErrDuplicate, interviewer.PhoneNumber)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) GetInterviewer(ctx context.Context, id int) (Interviewer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	interviewer, ok := s.interviewers[id]
+	if !ok {
+		return Interviewer{}, ErrNotFound
+	}
+	return interviewer, nil
+}
+
+func (s *memoryStore) GetInterviewerByPhoneNumber(ctx context.Context, phoneNumber string) (Interviewer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, interviewer := range sortedValues(s.interviewers) {
+		if interviewer.PhoneNumber == phoneNumber {
+			return interviewer, nil
+		}
+	}
+	return Interviewer{}, ErrNotFound
+}
+
+func (s *memoryStore) ListInterviewers(ctx context.Context) ([]Interviewer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return sortedValues(s.interviewers), nil
+}
+
+func (s *memoryStore) UpdateInterviewer(ctx context.Context, interviewer Interviewer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.interviewers[interviewer.ID]; !ok {
+		return nil
+	}
+	if err := s.checkInterviewerPhone(interviewer); err != nil {
+		return err
+	}
+	s.interviewers[interviewer.ID] = interviewer
+	return nil
+}
+
+func (s *memoryStore) DeleteInterviewer(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.interviewers, id)
+	return nil
+}
+
+func (s *memoryStore) CreateCandidate(ctx context.Context, candidate Candidate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkCandidatePhone(candidate); err != nil {
+		return err
+	}
+	id, err := nextID(s.candidates, candidate.ID)
+	if err != nil {
+		return err
+	}
+	candidate.ID = id
+	s.candidates[id] = candidate
+	return nil
+}
+
+func (s *memoryStore) checkCandidatePhone(candidate Candidate) error {
+	for _, existing := range s.candidates {
+		if existing.PhoneNumber == candidate.PhoneNumber && existing.ID != candidate.ID {
+			return fmt.Errorf("%w: phone number %s", ErrDuplicate, candidate.PhoneNumber)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) GetCandidate(ctx context.Context, id int) (Candidate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	candidate, ok := s.candidates[id]
+	if !ok {
+		return Candidate{}, ErrNotFound
+	}
+	return candidate, nil
+}
+
+func (s *memoryStore) GetCandidateByPhoneNumber(ctx context.Context, phoneNumber string) (Candidate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, candidate := range sortedValues(s.candidates) {
+		if candidate.PhoneNumber == phoneNumber {
+			return candidate, nil
+		}
+	}
+	return Candidate{}, ErrNotFound
+}
+
+func (s *memoryStore) ListCandidates(ctx context.Context) ([]Candidate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return sortedValues(s.candidates), nil
+}
+
+func (s *memoryStore) UpdateCandidate(ctx context.Context, candidate Candidate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.candidates[candidate.ID]; !ok {
+		return nil
+	}
+	if err := s.checkCandidatePhone(candidate); err != nil {
+		return err
+	}
+	s.candidates[candidate.ID] = candidate
+	return nil
+}
+
+func (s *memoryStore) DeleteCandidate(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.candidates, id)
+	return nil
+}
+
+func (s *memoryStore) CreateHR(ctx context.Context, hr HR) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkHRPhone(hr); err != nil {
+		return err
+	}
+	id, err := nextID(s.hrs, hr.ID)
+	if err != nil {
+		return err
+	}
+	hr.ID = id
+	s.hrs[id] = hr
+	return nil
+}
+
+func (s *memoryStore) checkHRPhone(hr HR) error {
+	for _, existing := range s.hrs {
+		if existing.PhoneNumber == hr.PhoneNumber && existing.ID != hr.ID {
+			return fmt.Errorf("%w: phone number %s", ErrDuplicate, hr.PhoneNumber)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) GetHR(ctx context.Context, id int) (HR, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hr, ok := s.hrs[id]
+	if !ok {
+		return HR{}, ErrNotFound
+	}
+	return hr, nil
+}
+
+func (s *memoryStore) GetHRByPhoneNumber(ctx context.Context, phoneNumber string) (HR, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, hr := range sortedValues(s.hrs) {
+		if hr.PhoneNumber == phoneNumber {
+			return hr, nil
+		}
+	}
+	return HR{}, ErrNotFound
+}
+
+func (s *memoryStore) ListHRs(ctx context.Context) ([]HR, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return sortedValues(s.hrs), nil
+}
+
+func (s *memoryStore) UpdateHR(ctx context.Context, hr HR) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hrs[hr.ID]; !ok {
+		return nil
+	}
+	if err := s.checkHRPhone(hr); err != nil {
+		return err
+	}
+	s.hrs[hr.ID] = hr
+	return nil
+}
+
+func (s *memoryStore) DeleteHR(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.hrs, id)
+	return nil
+}
+
+func (s *memoryStore) CreateInterview(ctx context.Context, interview Interview) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := nextID(s.interviews, interview.ID)
+	if err != nil {
+		return 0, err
+	}
+	interview.ID = id
+	interview.Status = interview.initialStatus()
+	interview.Sequence = 0
+	interview.Panel = append([]Panelist(nil), interview.Panel...)
+	s.interviews[id] = interview
+	return id, nil
+}
+
+func (s *memoryStore) GetInterview(ctx context.Context, id int) (Interview, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	interview, ok := s.interviews[id]
+	if !ok {
+		return Interview{}, ErrNotFound
+	}
+	interview.Panel = append([]Panelist(nil), interview.Panel...)
+	return interview, nil
+}
+
+func (s *memoryStore) ListInterviews(ctx context.Context, statuses ...string) ([]Interview, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wanted := map[string]bool{}
+	for _, status := range statuses {
+		wanted[status] = true
+	}
+	var interviews []Interview
+	for _, interview := range sortedValues(s.interviews) {
+		if len(statuses) == 0 || wanted[interview.Status] {
+			interviews = append(interviews, interview)
+		}
+	}
+	return interviews, nil
+}
+
+func (s *memoryStore) UpdateInterview(ctx context.Context, id int, interview Interview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.interviews[id]
+	if !ok {
+		return nil
+	}
+	interview.ID = id
+	interview.Status = interview.initialStatus()
+	interview.Sequence = existing.Sequence + 1
+	interview.Panel = append([]Panelist(nil), interview.Panel...)
+	s.interviews[id] = interview
+	return nil
+}
+
+func (s *memoryStore) DeleteInterview(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.interviews, id)
+	delete(s.assignments, id)
+	for key, r := range s.reschedules {
+		if r.InterviewID == id {
+			delete(s.reschedules, key)
+		}
+	}
+	for key, c := range s.statuses {
+		if c.InterviewID == id {
+			delete(s.statuses, key)
+		}
+	}
+	for key, r := range s.redemptions {
+		if r.InterviewID == id {
+			delete(s.redemptions, key)
+		}
+	}
+	for key, loop := range s.loops {
+		var rounds []LoopRound
+		for _, round := range loop.Rounds {
+			if round.InterviewID != id {
+				rounds = append(rounds, round)
+			}
+		}
+		switch {
+		case len(rounds) == len(loop.Rounds):
+		case len(rounds) == 0:
+			delete(s.loops, key)
+		default:
+			loop.Rounds = rounds
+			s.loops[key] = loop
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) FindConflicts(ctx context.Context, participants []Participant, start, end time.Time) ([]Conflict, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var conflicts []Conflict
+	for _, interview := range sortedValues(s.interviews) {
+		if interview.Status == StatusCancelled {
+			continue
+		}
+		conflicts = append(conflicts, conflictsWith(interview, participants, start, end)...)
+	}
+	return conflicts, nil
+}
+
+func (s *memoryStore) AddReschedule(ctx context.Context, reschedule Reschedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := nextID(s.reschedules, reschedule.ID)
+	if err != nil {
+		return err
+	}
+	reschedule.ID = id
+	s.reschedules[id] = reschedule
+	return nil
+}
+
+func (s *memoryStore) ListReschedules(ctx context.Context, interviewID int) ([]Reschedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var reschedules []Reschedule
+	for _, r := range sortedValues(s.reschedules) {
+		if r.InterviewID == interviewID {
+			reschedules = append(reschedules, r)
+		}
+	}
+	return reschedules, nil
+}
+
+func (s *memoryStore) SetInterviewStatus(ctx context.Context, change StatusChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interview, ok := s.interviews[change.InterviewID]
+	if !ok {
+		return ErrNotFound
+	}
+	id, err := nextID(s.statuses, change.ID)
+	if err != nil {
+		return err
+	}
+	change.ID = id
+	s.statuses[id] = change
+	interview.Status = change.ToStatus
+	if change.ToStatus == StatusCancelled {
+		interview.Sequence++
+	}
+	s.interviews[change.InterviewID] = interview
+	return nil
+}
+
+func (s *memoryStore) ListStatusChanges(ctx context.Context, interviewID int) ([]StatusChange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var changes []StatusChange
+	for _, c := range sortedValues(s.statuses) {
+		if c.InterviewID == interviewID {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+func (s *memoryStore) CreateWorkingHours(ctx context.Context, wh WorkingHours) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := nextID(s.workingHours, wh.ID)
+	if err != nil {
+		return 0, err
+	}
+	wh.ID = id
+	s.workingHours[id] = wh
+	return id, nil
+}
+
+func (s *memoryStore) ListWorkingHours(ctx context.Context, owner Participant) ([]WorkingHours, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hours []WorkingHours
+	for _, wh := range sortedValues(s.workingHours) {
+		if wh.owner() == owner {
+			hours = append(hours, wh)
+		}
+	}
+	sort.SliceStable(hours, func(i, j int) bool {
+		if hours[i].Weekday != hours[j].Weekday {
+			return hours[i].Weekday < hours[j].Weekday
+		}
+		return hours[i].StartTime < hours[j].StartTime
+	})
+	return hours, nil
+}
+
+func (s *memoryStore) UpdateWorkingHours(ctx context.Context, wh WorkingHours) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.workingHours[wh.ID]; !ok || existing.owner() != wh.owner() {
+		return ErrNotFound
+	}
+	s.workingHours[wh.ID] = wh
+	return nil
+}
+
+func (s *memoryStore) DeleteWorkingHours(ctx context.Context, owner Participant, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.workingHours[id]; !ok || existing.owner() != owner {
+		return ErrNotFound
+	}
+	delete(s.workingHours, id)
+	return nil
+}
+
+func (s *memoryStore) CreateTimeOff(ctx context.Context, off TimeOff) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := nextID(s.timeOff, off.ID)
+	if err != nil {
+		return 0, err
+	}
+	off.ID = id
+	s.timeOff[id] = off
+	return id, nil
+}
+
+func (s *memoryStore) ListTimeOff(ctx context.Context, owner Participant, from, to time.Time) ([]TimeOff, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var offs []TimeOff
+	for _, off := range sortedValues(s.timeOff) {
+		if off.owner() != owner {
+			continue
+		}
+		if (!to.IsZero() && !off.StartTime.Before(to)) || (!from.IsZero() && !off.EndTime.After(from)) {
+			continue
+		}
+		offs = append(offs, off)
+	}
+	sort.SliceStable(offs, func(i, j int) bool { return offs[i].StartTime.Before(offs[j].StartTime) })
+	return offs, nil
+}
+
+func (s *memoryStore) UpdateTimeOff(ctx context.Context, off TimeOff) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.timeOff[off.ID]; !ok || existing.owner() != off.owner() {
+		return ErrNotFound
+	}
+	s.timeOff[off.ID] = off
+	return nil
+}
+
+func (s *memoryStore) DeleteTimeOff(ctx context.Context, owner Participant, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.timeOff[id]; !ok || existing.owner() != owner {
+		return ErrNotFound
+	}
+	delete(s.timeOff, id)
+	return nil
+}
+
+func (s *memoryStore) SetInterviewerSkills(ctx context.Context, interviewerID int, skills []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.skills[interviewerID] = append([]string(nil), skills...)
+	return nil
+}
+
+func (s *memoryStore) GetInterviewerSkills(ctx context.Context, interviewerID int) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	skills := append([]string{}, s.skills[interviewerID]...)
+	sort.Strings(skills)
+	return skills, nil
+}
+
+func (s *memoryStore) FindInterviewersWithSkills(ctx context.Context, skills []string) ([]Interviewer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var interviewers []Interviewer
+	for _, interviewer := range sortedValues(s.interviewers) {
+		has := map[string]bool{}
+		for _, skill := range s.skills[interviewer.ID] {
+			has[skill] = true
+		}
+		eligible := true
+		for _, skill := range skills {
+			eligible = eligible && has[skill]
+		}
+		if eligible {
+			interviewers = append(interviewers, interviewer)
+		}
+	}
+	return interviewers, nil
+}
+
+func (s *memoryStore) AddAssignment(ctx context.Context, assignment Assignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.assignments[assignment.InterviewID]; ok {
+		return fmt.Errorf("%w: assignment for interview %d", ErrDuplicate, assignment.InterviewID)
+	}
+	s.assignments[assignment.InterviewID] = assignment
+	return nil
+}
+
+func (s *memoryStore) GetAssignment(ctx context.Context, interviewID int) (Assignment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	assignment, ok := s.assignments[interviewID]
+	if !ok {
+		return Assignment{}, ErrNotFound
+	}
+	return assignment, nil
+}
+
+func (s *memoryStore) LastAssignment(ctx context.Context, strategy string) (Assignment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var last Assignment
+	found := false
+	for _, a := range sortedValues(s.assignments) {
+		if a.Strategy == strategy && (!found || !a.CreatedAt.Before(last.CreatedAt)) {
+			last, found = a, true
+		}
+	}
+	if !found {
+		return Assignment{}, ErrNotFound
+	}
+	return last, nil
+}
+
+func (s *memoryStore) CreateLoop(ctx context.Context, loop Loop) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := nextID(s.loops, loop.ID)
+	if err != nil {
+		return 0, err
+	}
+	loop.ID = id
+	loop.Rounds = append([]LoopRound(nil), loop.Rounds...)
+	s.loops[id] = loop
+	return id, nil
+}
+
+func (s *memoryStore) GetLoop(ctx context.Context, id int) (Loop, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	loop, ok := s.loops[id]
+	if !ok {
+		return Loop{}, ErrNotFound
+	}
+	loop.Rounds = append([]LoopRound(nil), loop.Rounds...)
+	return loop, nil
+}
+
+func (s *memoryStore) ListLoops(ctx context.Context, candidateID int) ([]Loop, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var loops []Loop
+	for _, loop := range sortedValues(s.loops) {
+		if loop.CandidateID == candidateID {
+			loop.Rounds = append([]LoopRound(nil), loop.Rounds...)
+			loops = append(loops, loop)
+		}
+	}
+	return loops, nil
+}
+
+func (s *memoryStore) RedeemBookingLink(ctx context.Context, redemption BookingRedemption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.redemptions[redemption.LinkID]; ok {
+		return fmt.Errorf("%w: booking link %s", ErrDuplicate, redemption.LinkID)
+	}
+	s.redemptions[redemption.LinkID] = redemption
+	return nil
+}
+
+func (s *memoryStore) GetBookingRedemption(ctx context.Context, linkID string) (BookingRedemption, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	redemption, ok := s.redemptions[linkID]
+	if !ok {
+		return BookingRedemption{}, ErrNotFound
+	}
+	return redemption, nil
+}
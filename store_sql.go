@@ -0,0 +1,921 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sqlStore implements Store on top of the interview_system schema. The
+// queries stick to SQL understood by both MySQL and SQLite, so the same
+// implementation backs the mysql and sqlite stores.
+type sqlStore struct {
+	// db runs the queries: the pool, or the transaction inside Atomically.
+	db querier
+	// pool is nil for a store bound to a transaction.
+	pool    *sql.DB
+	dialect string
+}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func newSQLStore(db *sql.DB, dialect string) *sqlStore {
+	return &sqlStore{db: db, pool: db, dialect: dialect}
+}
+
+// participantTables maps each role to the table its people are stored in.
+var participantTables = map[string]string{
+	RoleInterviewer: "interviewer",
+	RoleCandidate:   "candidate",
+	RoleHR:          "hr",
+}
+
+// Atomically runs fn in a transaction. On MySQL the participants' rows are
+// locked with SELECT ... FOR UPDATE, in a fixed order to avoid deadlocks, so
+// concurrent bookings for the same person queue up behind each other.
+// SQLite transactions take the database write lock up front (see
+// database.Connect), which serialises them entirely.
+func (s *sqlStore) Atomically(ctx context.Context, participants []Participant, fn func(Store) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+
+	tx, err := s.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	lockClause := ""
+	if s.dialect == "mysql" {
+		lockClause = " FOR UPDATE"
+	}
+	for _, p := range sortedParticipants(participants) {
+		table, ok := participantTables[p.Role]
+		if !ok {
+			return fmt.Errorf("unknown participant role %q", p.Role)
+		}
+		var id int
+		err := tx.QueryRowContext(ctx, "SELECT id FROM "+table+" WHERE id = ?"+lockClause, p.ID).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s %d does not exist", ErrUnknownParticipant, roleTitle(p.Role), p.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := fn(&sqlStore{db: tx, dialect: s.dialect}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// insertID maps a zero id to NULL so the database assigns one. MySQL does
+// this for 0 as well, but SQLite would store the 0.
+func insertID(id int) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// joinChannels stores a person's notification channels as one
+// comma-separated column.
+func joinChannels(channels []string) string {
+	if len(channels) == 0 {
+		return ChannelSMS
+	}
+	return strings.Join(channels, ",")
+}
+
+func splitChannels(column string) []string {
+	if column == "" {
+		return nil
+	}
+	return strings.Split(column, ",")
+}
+
+const interviewerColumns = "id, name, phone_number, time_zone, notification_channels, email"
+
+func scanInterviewer(row rowScanner) (Interviewer, error) {
+	var interviewer Interviewer
+	var channels string
+	err := row.Scan(&interviewer.ID, &interviewer.Name, &interviewer.PhoneNumber, &interviewer.TimeZone, &channels, &interviewer.Email)
+	interviewer.NotificationChannels = splitChannels(channels)
+	return interviewer, notFound(err)
+}
+
+func (s *sqlStore) CreateInterviewer(ctx context.Context, interviewer Interviewer) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO interviewer ("+interviewerColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		insertID(interviewer.ID), interviewer.Name, interviewer.PhoneNumber, interviewer.TimeZone, joinChannels(interviewer.NotificationChannels), interviewer.Email)
+	return err
+}
+
+func (s *sqlStore) GetInterviewer(ctx context.Context, id int) (Interviewer, error) {
+	return scanInterviewer(s.db.QueryRowContext(ctx, "SELECT "+interviewerColumns+" FROM interviewer WHERE id = ?", id))
+}
+
+func (s *sqlStore) GetInterviewerByPhoneNumber(ctx context.Context, phoneNumber string) (Interviewer, error) {
+	return scanInterviewer(s.db.QueryRowContext(ctx, "SELECT "+interviewerColumns+" FROM interviewer WHERE phone_number = ? LIMIT 1", phoneNumber))
+}
+
+func (s *sqlStore) ListInterviewers(ctx context.Context) ([]Interviewer, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+interviewerColumns+" FROM interviewer")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interviewers []Interviewer
+	for rows.Next() {
+		interviewer, err := scanInterviewer(rows)
+		if err != nil {
+			return nil, err
+		}
+		interviewers = append(interviewers, interviewer)
+	}
+	return interviewers, rows.Err()
+}
+
+func (s *sqlStore) UpdateInterviewer(ctx context.Context, interviewer Interviewer) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE interviewer SET name = ?, phone_number = ?, time_zone = ?, notification_channels = ?, email = ? WHERE id = ?",
+		interviewer.Name, interviewer.PhoneNumber, interviewer.TimeZone, joinChannels(interviewer.NotificationChannels), interviewer.Email, interviewer.ID)
+	return err
+}
+
+func (s *sqlStore) DeleteInterviewer(ctx context.Context, id int) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM interviewer WHERE id = ?", id)
+	return err
+}
+
+const candidateColumns = "id, name, phone_number, time_zone, notification_channels, email"
+
+func scanCandidate(row rowScanner) (Candidate, error) {
+	var candidate Candidate
+	var channels string
+	err := row.Scan(&candidate.ID, &candidate.Name, &candidate.PhoneNumber, &candidate.TimeZone, &channels, &candidate.Email)
+	candidate.NotificationChannels = splitChannels(channels)
+	return candidate, notFound(err)
+}
+
+func (s *sqlStore) CreateCandidate(ctx context.Context, candidate Candidate) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO candidate ("+candidateColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		insertID(candidate.ID), candidate.Name, candidate.PhoneNumber, candidate.TimeZone, joinChannels(candidate.NotificationChannels), candidate.Email)
+	return err
+}
+
+func (s *sqlStore) GetCandidate(ctx context.Context, id int) (Candidate, error) {
+	return scanCandidate(s.db.QueryRowContext(ctx, "SELECT "+candidateColumns+" FROM candidate WHERE id = ?", id))
+}
+
+func (s *sqlStore) GetCandidateByPhoneNumber(ctx context.Context, phoneNumber string) (Candidate, error) {
+	return scanCandidate(s.db.QueryRowContext(ctx, "SELECT "+candidateColumns+" FROM candidate WHERE phone_number = ? LIMIT 1", phoneNumber))
+}
+
+func (s *sqlStore) ListCandidates(ctx context.Context) ([]Candidate, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+candidateColumns+" FROM candidate")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []Candidate
+	for rows.Next() {
+		candidate, err := scanCandidate(rows)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+func (s *sqlStore) UpdateCandidate(ctx context.Context, candidate Candidate) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE candidate SET name = ?, phone_number = ?, time_zone = ?, notification_channels = ?, email = ? WHERE id = ?",
+		candidate.Name, candidate.PhoneNumber, candidate.TimeZone, joinChannels(candidate.NotificationChannels), candidate.Email, candidate.ID)
+	return err
+}
+
+func (s *sqlStore) DeleteCandidate(ctx context.Context, id int) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM candidate WHERE id = ?", id)
+	return err
+}
+
+const hrColumns = "id, name, phone_number, time_zone, notification_channels, email"
+
+func scanHR(row rowScanner) (HR, error) {
+	var hr HR
+	var channels string
+	err := row.Scan(&hr.ID, &hr.Name, &hr.PhoneNumber, &hr.TimeZone, &channels, &hr.Email)
+	hr.NotificationChannels = splitChannels(channels)
+	return hr, notFound(err)
+}
+
+func (s *sqlStore) CreateHR(ctx context.Context, hr HR) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO hr ("+hrColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		insertID(hr.ID), hr.Name, hr.PhoneNumber, hr.TimeZone, joinChannels(hr.NotificationChannels), hr.Email)
+	return err
+}
+
+func (s *sqlStore) GetHR(ctx context.Context, id int) (HR, error) {
+	return scanHR(s.db.QueryRowContext(ctx, "SELECT "+hrColumns+" FROM hr WHERE id = ?", id))
+}
+
+func (s *sqlStore) GetHRByPhoneNumber(ctx context.Context, phoneNumber string) (HR, error) {
+	return scanHR(s.db.QueryRowContext(ctx, "SELECT "+hrColumns+" FROM hr WHERE phone_number = ? LIMIT 1", phoneNumber))
+}
+
+func (s *sqlStore) ListHRs(ctx context.Context) ([]HR, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+hrColumns+" FROM hr")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hrs []HR
+	for rows.Next() {
+		hr, err := scanHR(rows)
+		if err != nil {
+			return nil, err
+		}
+		hrs = append(hrs, hr)
+	}
+	return hrs, rows.Err()
+}
+
+func (s *sqlStore) UpdateHR(ctx context.Context, hr HR) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE hr SET name = ?, phone_number = ?, time_zone = ?, notification_channels = ?, email = ? WHERE id = ?",
+		hr.Name, hr.PhoneNumber, hr.TimeZone, joinChannels(hr.NotificationChannels), hr.Email, hr.ID)
+	return err
+}
+
+func (s *sqlStore) DeleteHR(ctx context.Context, id int) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM hr WHERE id = ?", id)
+	return err
+}
+
+const interviewColumns = "id, interviewer_id, candidate_id, hr_id, scheduled_time, duration_minutes, rescheduled, interview_link, status, calendar_sequence"
+
+func scanInterview(row rowScanner) (Interview, error) {
+	var interview Interview
+	err := row.Scan(&interview.ID, &interview.InterviewerID, &interview.CandidateID, &interview.HRID,
+		&interview.ScheduledTime, &interview.DurationMinutes, &interview.Rescheduled, &interview.InterviewLink, &interview.Status, &interview.Sequence)
+	interview.ScheduledTime = interview.ScheduledTime.UTC()
+	interview.EndTime = interview.ScheduledTime.Add(interview.Duration())
+	return interview, notFound(err)
+}
+
+func (s *sqlStore) CreateInterview(ctx context.Context, interview Interview) (int, error) {
+	var id int64
+	err := s.inTx(ctx, func(q querier) error {
+		res, err := q.ExecContext(ctx, "INSERT INTO interview ("+interviewColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0)",
+			insertID(interview.ID), interview.InterviewerID, interview.CandidateID, interview.HRID,
+			interview.ScheduledTime, interview.DurationMinutes, interview.Rescheduled, interview.InterviewLink, interview.initialStatus())
+		if err != nil {
+			return err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		return insertPanel(ctx, q, int(id), interview.Panel)
+	})
+	return int(id), err
+}
+
+// insertPanel stores the panel of interview id.
+func insertPanel(ctx context.Context, q querier, id int, panel []Panelist) error {
+	for _, p := range panel {
+		_, err := q.ExecContext(ctx, "INSERT INTO interview_panelist (interview_id, interviewer_id, panel_role) VALUES (?, ?, ?)",
+			id, p.InterviewerID, p.Role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) GetInterview(ctx context.Context, id int) (Interview, error) {
+	interviews, err := s.queryInterviews(ctx, "SELECT "+interviewColumns+" FROM interview WHERE id = ?", id)
+	if err != nil {
+		return Interview{}, err
+	}
+	if len(interviews) == 0 {
+		return Interview{}, ErrNotFound
+	}
+	return interviews[0], nil
+}
+
+func (s *sqlStore) ListInterviews(ctx context.Context, statuses ...string) ([]Interview, error) {
+	if len(statuses) == 0 {
+		return s.queryInterviews(ctx, "SELECT "+interviewColumns+" FROM interview ORDER BY id")
+	}
+	args := make([]any, len(statuses))
+	for i, status := range statuses {
+		args[i] = status
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	return s.queryInterviews(ctx, "SELECT "+interviewColumns+" FROM interview WHERE status IN ("+placeholders+") ORDER BY id", args...)
+}
+
+// queryInterviews runs a query selecting interviewColumns and loads the
+// panels of the interviews it returns.
+func (s *sqlStore) queryInterviews(ctx context.Context, query string, args ...any) ([]Interview, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interviews []Interview
+	for rows.Next() {
+		interview, err := scanInterview(rows)
+		if err != nil {
+			return nil, err
+		}
+		interviews = append(interviews, interview)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// A transaction cannot run a second query while rows are still open.
+	rows.Close()
+
+	return interviews, s.loadPanels(ctx, interviews)
+}
+
+// loadPanels fills in the Panel of each interview.
+func (s *sqlStore) loadPanels(ctx context.Context, interviews []Interview) error {
+	if len(interviews) == 0 {
+		return nil
+	}
+
+	index := map[int]int{}
+	args := make([]any, len(interviews))
+	for i, interview := range interviews {
+		index[interview.ID] = i
+		args[i] = interview.ID
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	rows, err := s.db.QueryContext(ctx, "SELECT interview_id, interviewer_id, panel_role FROM interview_panelist WHERE interview_id IN ("+placeholders+") ORDER BY interview_id, interviewer_id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var p Panelist
+		if err := rows.Scan(&id, &p.InterviewerID, &p.Role); err != nil {
+			return err
+		}
+		interview := &interviews[index[id]]
+		if p.Role == PanelLead {
+			interview.Panel = append([]Panelist{p}, interview.Panel...)
+		} else {
+			interview.Panel = append(interview.Panel, p)
+		}
+	}
+	return rows.Err()
+}
+
+func (s *sqlStore) UpdateInterview(ctx context.Context, id int, interview Interview) error {
+	return s.inTx(ctx, func(q querier) error {
+		// Like the other updates, updating a missing interview does nothing.
+		var existing int
+		err := q.QueryRowContext(ctx, "SELECT id FROM interview WHERE id = ?", id).Scan(&existing)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, "UPDATE interview SET interviewer_id=?, candidate_id=?, hr_id=?, scheduled_time=?, duration_minutes=?, rescheduled=?, interview_link=?, status=?, calendar_sequence=calendar_sequence+1 WHERE id=?",
+			interview.InterviewerID, interview.CandidateID, interview.HRID,
+			interview.ScheduledTime, interview.DurationMinutes, interview.Rescheduled, interview.InterviewLink, interview.initialStatus(), id)
+		if err != nil {
+			return err
+		}
+		if _, err := q.ExecContext(ctx, "DELETE FROM interview_panelist WHERE interview_id = ?", id); err != nil {
+			return err
+		}
+		return insertPanel(ctx, q, id, interview.Panel)
+	})
+}
+
+// interviewHistoryTables hold rows about an interview, keyed by
+// interview_id, that are removed along with it. That includes its loop
+// round: the other rounds keep their positions.
+var interviewHistoryTables = []string{
+	"interview_panelist",
+	"interview_reschedule",
+	"interview_status_change",
+	"interview_assignment",
+	"booking_link_redemption",
+	"interview_loop_round",
+}
+
+func (s *sqlStore) DeleteInterview(ctx context.Context, id int) error {
+	return s.inTx(ctx, func(q querier) error {
+		var loopID int
+		err := q.QueryRowContext(ctx, "SELECT loop_id FROM interview_loop_round WHERE interview_id = ?", id).Scan(&loopID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		for _, table := range interviewHistoryTables {
+			if _, err := q.ExecContext(ctx, "DELETE FROM "+table+" WHERE interview_id = ?", id); err != nil {
+				return err
+			}
+		}
+		if loopID != 0 {
+			// A loop left without rounds goes as well.
+			_, err := q.ExecContext(ctx, "DELETE FROM interview_loop WHERE id = ? AND NOT EXISTS (SELECT 1 FROM interview_loop_round WHERE loop_id = ?)", loopID, loopID)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = q.ExecContext(ctx, "DELETE FROM interview WHERE id=?", id)
+		return err
+	})
+}
+
+// participantColumns maps each role to the interview column holding it.
+var participantColumns = map[string]string{
+	RoleInterviewer: "interviewer_id",
+	RoleCandidate:   "candidate_id",
+	RoleHR:          "hr_id",
+}
+
+func (s *sqlStore) FindConflicts(ctx context.Context, participants []Participant, start, end time.Time) ([]Conflict, error) {
+	if len(participants) == 0 {
+		return nil, nil
+	}
+
+	var conditions []string
+	var args []any
+	for _, p := range participants {
+		column, ok := participantColumns[p.Role]
+		if !ok {
+			return nil, fmt.Errorf("unknown participant role %q", p.Role)
+		}
+		if p.Role == RoleInterviewer {
+			// Interviewers also take part as panelists.
+			conditions = append(conditions, "("+column+" = ? OR id IN (SELECT interview_id FROM interview_panelist WHERE interviewer_id = ?))")
+			args = append(args, p.ID, p.ID)
+			continue
+		}
+		conditions = append(conditions, column+" = ?")
+		args = append(args, p.ID)
+	}
+
+	// Only interviews starting inside the window can overlap it, since none
+	// is longer than maxInterviewDuration. The exact check happens below.
+	query := "SELECT " + interviewColumns + " FROM interview WHERE (" + strings.Join(conditions, " OR ") + ") AND scheduled_time < ? AND scheduled_time > ? AND status <> ? ORDER BY scheduled_time"
+	args = append(args, end.UTC(), start.Add(-maxInterviewDuration).UTC(), StatusCancelled)
+
+	interviews, err := s.queryInterviews(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []Conflict
+	for _, interview := range interviews {
+		conflicts = append(conflicts, conflictsWith(interview, participants, start, end)...)
+	}
+	return conflicts, nil
+}
+
+const rescheduleColumns = "id, interview_id, previous_time, previous_duration_minutes, new_time, new_duration_minutes, reason, actor, created_at"
+
+func (s *sqlStore) AddReschedule(ctx context.Context, reschedule Reschedule) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO interview_reschedule ("+rescheduleColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		insertID(reschedule.ID), reschedule.InterviewID, reschedule.PreviousTime.UTC(), reschedule.PreviousDurationMinutes,
+		reschedule.NewTime.UTC(), reschedule.NewDurationMinutes, reschedule.Reason, reschedule.Actor, reschedule.CreatedAt.UTC())
+	return err
+}
+
+func (s *sqlStore) ListReschedules(ctx context.Context, interviewID int) ([]Reschedule, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+rescheduleColumns+" FROM interview_reschedule WHERE interview_id = ? ORDER BY id", interviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reschedules []Reschedule
+	for rows.Next() {
+		var r Reschedule
+		err := rows.Scan(&r.ID, &r.InterviewID, &r.PreviousTime, &r.PreviousDurationMinutes,
+			&r.NewTime, &r.NewDurationMinutes, &r.Reason, &r.Actor, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		r.PreviousTime, r.NewTime, r.CreatedAt = r.PreviousTime.UTC(), r.NewTime.UTC(), r.CreatedAt.UTC()
+		reschedules = append(reschedules, r)
+	}
+	return reschedules, rows.Err()
+}
+
+const statusChangeColumns = "id, interview_id, from_status, to_status, reason, actor, created_at"
+
+func (s *sqlStore) SetInterviewStatus(ctx context.Context, change StatusChange) error {
+	return s.inTx(ctx, func(q querier) error {
+		var existing int
+		err := q.QueryRowContext(ctx, "SELECT id FROM interview WHERE id = ?", change.InterviewID).Scan(&existing)
+		if err != nil {
+			return notFound(err)
+		}
+		query := "UPDATE interview SET status = ? WHERE id = ?"
+		if change.ToStatus == StatusCancelled {
+			query = "UPDATE interview SET status = ?, calendar_sequence = calendar_sequence + 1 WHERE id = ?"
+		}
+		if _, err := q.ExecContext(ctx, query, change.ToStatus, change.InterviewID); err != nil {
+			return err
+		}
+		_, err = q.ExecContext(ctx, "INSERT INTO interview_status_change ("+statusChangeColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+			insertID(change.ID), change.InterviewID, change.FromStatus, change.ToStatus, change.Reason, change.Actor, change.CreatedAt.UTC())
+		return err
+	})
+}
+
+func (s *sqlStore) ListStatusChanges(ctx context.Context, interviewID int) ([]StatusChange, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+statusChangeColumns+" FROM interview_status_change WHERE interview_id = ? ORDER BY id", interviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []StatusChange
+	for rows.Next() {
+		var c StatusChange
+		if err := rows.Scan(&c.ID, &c.InterviewID, &c.FromStatus, &c.ToStatus, &c.Reason, &c.Actor, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.CreatedAt = c.CreatedAt.UTC()
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+const workingHoursColumns = "id, owner_role, owner_id, weekday, start_minute, end_minute"
+
+func (s *sqlStore) CreateWorkingHours(ctx context.Context, wh WorkingHours) (int, error) {
+	res, err := s.db.ExecContext(ctx, "INSERT INTO working_hours ("+workingHoursColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		insertID(wh.ID), wh.OwnerRole, wh.OwnerID, int(wh.Weekday), int(wh.StartTime), int(wh.EndTime))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+func (s *sqlStore) ListWorkingHours(ctx context.Context, owner Participant) ([]WorkingHours, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+workingHoursColumns+" FROM working_hours WHERE owner_role = ? AND owner_id = ? ORDER BY weekday, start_minute",
+		owner.Role, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hours []WorkingHours
+	for rows.Next() {
+		var wh WorkingHours
+		err := rows.Scan(&wh.ID, &wh.OwnerRole, &wh.OwnerID, &wh.Weekday, &wh.StartTime, &wh.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		hours = append(hours, wh)
+	}
+	return hours, rows.Err()
+}
+
+func (s *sqlStore) UpdateWorkingHours(ctx context.Context, wh WorkingHours) error {
+	if err := s.checkOwned(ctx, "working_hours", wh.owner(), wh.ID); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, "UPDATE working_hours SET weekday = ?, start_minute = ?, end_minute = ? WHERE id = ?",
+		int(wh.Weekday), int(wh.StartTime), int(wh.EndTime), wh.ID)
+	return err
+}
+
+func (s *sqlStore) DeleteWorkingHours(ctx context.Context, owner Participant, id int) error {
+	if err := s.checkOwned(ctx, "working_hours", owner, id); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, "DELETE FROM working_hours WHERE id = ?", id)
+	return err
+}
+
+const timeOffColumns = "id, owner_role, owner_id, kind, start_time, end_time, reason"
+
+func (s *sqlStore) CreateTimeOff(ctx context.Context, off TimeOff) (int, error) {
+	res, err := s.db.ExecContext(ctx, "INSERT INTO time_off ("+timeOffColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		insertID(off.ID), off.OwnerRole, off.OwnerID, off.Kind, off.StartTime.UTC(), off.EndTime.UTC(), off.Reason)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+func (s *sqlStore) ListTimeOff(ctx context.Context, owner Participant, from, to time.Time) ([]TimeOff, error) {
+	query := "SELECT " + timeOffColumns + " FROM time_off WHERE owner_role = ? AND owner_id = ?"
+	args := []any{owner.Role, owner.ID}
+	if !to.IsZero() {
+		query += " AND start_time < ?"
+		args = append(args, to.UTC())
+	}
+	if !from.IsZero() {
+		query += " AND end_time > ?"
+		args = append(args, from.UTC())
+	}
+
+	rows, err := s.db.QueryContext(ctx, query+" ORDER BY start_time", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var offs []TimeOff
+	for rows.Next() {
+		var off TimeOff
+		err := rows.Scan(&off.ID, &off.OwnerRole, &off.OwnerID, &off.Kind, &off.StartTime, &off.EndTime, &off.Reason)
+		if err != nil {
+			return nil, err
+		}
+		off.StartTime, off.EndTime = off.StartTime.UTC(), off.EndTime.UTC()
+		offs = append(offs, off)
+	}
+	return offs, rows.Err()
+}
+
+func (s *sqlStore) UpdateTimeOff(ctx context.Context, off TimeOff) error {
+	if err := s.checkOwned(ctx, "time_off", off.owner(), off.ID); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, "UPDATE time_off SET kind = ?, start_time = ?, end_time = ?, reason = ? WHERE id = ?",
+		off.Kind, off.StartTime.UTC(), off.EndTime.UTC(), off.Reason, off.ID)
+	return err
+}
+
+func (s *sqlStore) DeleteTimeOff(ctx context.Context, owner Participant, id int) error {
+	if err := s.checkOwned(ctx, "time_off", owner, id); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, "DELETE FROM time_off WHERE id = ?", id)
+	return err
+}
+
+// checkOwned returns ErrNotFound unless the availability entry id in table
+// belongs to owner. MySQL reports zero affected rows for an UPDATE that
+// changes nothing, so the row count cannot be used for this.
+func (s *sqlStore) checkOwned(ctx context.Context, table string, owner Participant, id int) error {
+	var found int
+	err := s.db.QueryRowContext(ctx, "SELECT id FROM "+table+" WHERE id = ? AND owner_role = ? AND owner_id = ?",
+		id, owner.Role, owner.ID).Scan(&found)
+	return notFound(err)
+}
+
+// inTx runs fn in a transaction, or directly when the store is already
+// bound to one.
+func (s *sqlStore) inTx(ctx context.Context, fn func(q querier) error) error {
+	if s.pool == nil {
+		return fn(s.db)
+	}
+	tx, err := s.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqlStore) SetInterviewerSkills(ctx context.Context, interviewerID int, skills []string) error {
+	return s.inTx(ctx, func(q querier) error {
+		if _, err := q.ExecContext(ctx, "DELETE FROM interviewer_skill WHERE interviewer_id = ?", interviewerID); err != nil {
+			return err
+		}
+		for _, skill := range skills {
+			if _, err := q.ExecContext(ctx, "INSERT INTO interviewer_skill (interviewer_id, skill) VALUES (?, ?)", interviewerID, skill); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) GetInterviewerSkills(ctx context.Context, interviewerID int) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT skill FROM interviewer_skill WHERE interviewer_id = ? ORDER BY skill", interviewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := []string{}
+	for rows.Next() {
+		var skill string
+		if err := rows.Scan(&skill); err != nil {
+			return nil, err
+		}
+		skills = append(skills, skill)
+	}
+	return skills, rows.Err()
+}
+
+func (s *sqlStore) FindInterviewersWithSkills(ctx context.Context, skills []string) ([]Interviewer, error) {
+	query := "SELECT " + interviewerColumns + " FROM interviewer"
+	var args []any
+	if len(skills) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(skills)), ", ")
+		query += " WHERE id IN (SELECT interviewer_id FROM interviewer_skill WHERE skill IN (" + placeholders + ") GROUP BY interviewer_id HAVING COUNT(*) = ?)"
+		for _, skill := range skills {
+			args = append(args, skill)
+		}
+		args = append(args, len(skills))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interviewers []Interviewer
+	for rows.Next() {
+		interviewer, err := scanInterviewer(rows)
+		if err != nil {
+			return nil, err
+		}
+		interviewers = append(interviewers, interviewer)
+	}
+	return interviewers, rows.Err()
+}
+
+const assignmentColumns = "interview_id, interviewer_id, strategy, skills, reason, created_at"
+
+func scanAssignment(row rowScanner) (Assignment, error) {
+	var a Assignment
+	var skills string
+	err := row.Scan(&a.InterviewID, &a.InterviewerID, &a.Strategy, &skills, &a.Reason, &a.CreatedAt)
+	a.Skills = []string{}
+	if skills != "" {
+		a.Skills = strings.Split(skills, ",")
+	}
+	a.CreatedAt = a.CreatedAt.UTC()
+	return a, notFound(err)
+}
+
+func (s *sqlStore) AddAssignment(ctx context.Context, assignment Assignment) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO interview_assignment ("+assignmentColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		assignment.InterviewID, assignment.InterviewerID, assignment.Strategy, strings.Join(assignment.Skills, ","),
+		assignment.Reason, assignment.CreatedAt.UTC())
+	return err
+}
+
+func (s *sqlStore) GetAssignment(ctx context.Context, interviewID int) (Assignment, error) {
+	return scanAssignment(s.db.QueryRowContext(ctx, "SELECT "+assignmentColumns+" FROM interview_assignment WHERE interview_id = ?", interviewID))
+}
+
+func (s *sqlStore) LastAssignment(ctx context.Context, strategy string) (Assignment, error) {
+	return scanAssignment(s.db.QueryRowContext(ctx, "SELECT "+assignmentColumns+" FROM interview_assignment WHERE strategy = ? ORDER BY created_at DESC, interview_id DESC LIMIT 1", strategy))
+}
+
+const loopColumns = "id, candidate_id, hr_id, name, same_day, created_at"
+
+func (s *sqlStore) CreateLoop(ctx context.Context, loop Loop) (int, error) {
+	var id int64
+	err := s.inTx(ctx, func(q querier) error {
+		res, err := q.ExecContext(ctx, "INSERT INTO interview_loop ("+loopColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+			insertID(loop.ID), loop.CandidateID, loop.HRID, loop.Name, loop.SameDay, loop.CreatedAt.UTC())
+		if err != nil {
+			return err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		for _, round := range loop.Rounds {
+			_, err := q.ExecContext(ctx, "INSERT INTO interview_loop_round (loop_id, position, name, interview_id) VALUES (?, ?, ?, ?)",
+				id, round.Position, round.Name, round.InterviewID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return int(id), err
+}
+
+func (s *sqlStore) GetLoop(ctx context.Context, id int) (Loop, error) {
+	loops, err := s.queryLoops(ctx, "SELECT "+loopColumns+" FROM interview_loop WHERE id = ?", id)
+	if err != nil {
+		return Loop{}, err
+	}
+	if len(loops) == 0 {
+		return Loop{}, ErrNotFound
+	}
+	return loops[0], nil
+}
+
+func (s *sqlStore) ListLoops(ctx context.Context, candidateID int) ([]Loop, error) {
+	return s.queryLoops(ctx, "SELECT "+loopColumns+" FROM interview_loop WHERE candidate_id = ? ORDER BY id", candidateID)
+}
+
+// queryLoops runs a query selecting loopColumns and loads the rounds of the
+// loops it returns.
+func (s *sqlStore) queryLoops(ctx context.Context, query string, args ...any) ([]Loop, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var loops []Loop
+	for rows.Next() {
+		var loop Loop
+		err := rows.Scan(&loop.ID, &loop.CandidateID, &loop.HRID, &loop.Name, &loop.SameDay, &loop.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		loop.CreatedAt = loop.CreatedAt.UTC()
+		loops = append(loops, loop)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range loops {
+		loops[i].Rounds, err = s.loopRounds(ctx, loops[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return loops, nil
+}
+
+func (s *sqlStore) loopRounds(ctx context.Context, loopID int) ([]LoopRound, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT position, name, interview_id FROM interview_loop_round WHERE loop_id = ? ORDER BY position", loopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rounds []LoopRound
+	for rows.Next() {
+		var round LoopRound
+		if err := rows.Scan(&round.Position, &round.Name, &round.InterviewID); err != nil {
+			return nil, err
+		}
+		rounds = append(rounds, round)
+	}
+	return rounds, rows.Err()
+}
+
+func (s *sqlStore) RedeemBookingLink(ctx context.Context, redemption BookingRedemption) error {
+	return s.inTx(ctx, func(q querier) error {
+		var used int
+		err := q.QueryRowContext(ctx, "SELECT COUNT(*) FROM booking_link_redemption WHERE link_id = ?", redemption.LinkID).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used > 0 {
+			return fmt.Errorf("%w: booking link %s", ErrDuplicate, redemption.LinkID)
+		}
+		_, err = q.ExecContext(ctx, "INSERT INTO booking_link_redemption (link_id, interview_id, redeemed_at) VALUES (?, ?, ?)",
+			redemption.LinkID, redemption.InterviewID, redemption.RedeemedAt.UTC())
+		return err
+	})
+}
+
+func (s *sqlStore) GetBookingRedemption(ctx context.Context, linkID string) (BookingRedemption, error) {
+	var redemption BookingRedemption
+	err := s.db.QueryRowContext(ctx, "SELECT link_id, interview_id, redeemed_at FROM booking_link_redemption WHERE link_id = ?", linkID).
+		Scan(&redemption.LinkID, &redemption.InterviewID, &redemption.RedeemedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return BookingRedemption{}, ErrNotFound
+	}
+	redemption.RedeemedAt = redemption.RedeemedAt.UTC()
+	return redemption, err
+}
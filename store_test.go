@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"example.com/database"
+)
+
+// mysqlTestDSN names the environment variable holding the DSN of a MySQL
+// database to run the store tests against as well. The tests roll back every
+// migration in it before each case, so it must be a disposable database.
+const mysqlTestDSN = "INTERVIEW_TEST_MYSQL_DSN"
+
+// storeBackend opens an empty, fully migrated store for one test case.
+type storeBackend struct {
+	name string
+	open func(t *testing.T) Store
+}
+
+// storeBackends lists the stores to test: memory, sqlite and, when
+// mysqlTestDSN is set, mysql.
+func storeBackends() []storeBackend {
+	backends := []storeBackend{
+		{"memory", func(t *testing.T) Store { return newMemoryStore() }},
+		{"sqlite", func(t *testing.T) Store { return newSQLStore(openTestDB(t, "sqlite"), "sqlite") }},
+	}
+	if os.Getenv(mysqlTestDSN) != "" {
+		backends = append(backends, storeBackend{"mysql", func(t *testing.T) Store {
+			return newSQLStore(openTestDB(t, "mysql"), "mysql")
+		}})
+	}
+	return backends
+}
+
+// sqlDialects lists the SQL dialects to test, as storeBackends does.
+func sqlDialects() []string {
+	dialects := []string{"sqlite"}
+	if os.Getenv(mysqlTestDSN) != "" {
+		dialects = append(dialects, "mysql")
+	}
+	return dialects
+}
+
+// openTestDB opens an empty database of dialect with every migration
+// applied: a new file for sqlite, and the mysqlTestDSN database, emptied
+// first, for mysql.
+func openTestDB(t *testing.T, dialect string) *sql.DB {
+	t.Helper()
+
+	cfg := database.DefaultConfig()
+	cfg.ConnectRetries = 0
+	switch dialect {
+	case "sqlite":
+		cfg.DSN = filepath.Join(t.TempDir(), "interviews.db")
+	case "mysql":
+		cfg.DSN = os.Getenv(mysqlTestDSN)
+	}
+	db, err := database.Open(dialect, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	for {
+		m, err := database.MigrateDown(ctx, db, dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m == nil {
+			break
+		}
+	}
+	if _, err := database.MigrateUp(ctx, db, dialect); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testTime is a fixed start time, truncated to the second as the SQL
+// stores keep it.
+var testTime = time.Date(2030, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+// seedPeople creates interviewers 1 and 2, candidates 1 and 2 and HR 1.
+func seedPeople(t *testing.T, ctx context.Context, store Store) {
+	t.Helper()
+
+	for _, i := range []Interviewer{
+		{ID: 1, Name: "Ada", PhoneNumber: "+15550000001", TimeZone: "UTC", NotificationChannels: []string{ChannelSMS}},
+		{ID: 2, Name: "Grace", PhoneNumber: "+15550000002", TimeZone: "UTC", NotificationChannels: []string{ChannelSMS}},
+	} {
+		if err := store.CreateInterviewer(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []Candidate{
+		{ID: 1, Name: "Alan", PhoneNumber: "+15550000011", TimeZone: "UTC", NotificationChannels: []string{ChannelSMS}},
+		{ID: 2, Name: "Edsger", PhoneNumber: "+15550000012", TimeZone: "UTC", NotificationChannels: []string{ChannelSMS}},
+	} {
+		if err := store.CreateCandidate(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hr := HR{ID: 1, Name: "Barbara", PhoneNumber: "+15550000021", TimeZone: "UTC", NotificationChannels: []string{ChannelSMS}}
+	if err := store.CreateHR(ctx, hr); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testInterview is an hour long interview of interviewer 1, candidate 1 and
+// HR 1 starting at start.
+func testInterview(start time.Time) Interview {
+	return Interview{
+		InterviewerID:   1,
+		CandidateID:     1,
+		HRID:            1,
+		ScheduledTime:   start,
+		DurationMinutes: 60,
+		EndTime:         start.Add(time.Hour),
+		InterviewLink:   "https://meet.example.com/abc",
+		Panel:           []Panelist{{1, PanelLead}},
+	}
+}
+
+func TestStorePeople(t *testing.T) {
+	for _, backend := range storeBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.open(t)
+			seedPeople(t, ctx, store)
+
+			tests := []struct {
+				name    string
+				get     func(id int) (any, error)
+				byPhone func(phone string) (any, error)
+				list    func() (int, error)
+				update  func() error
+				delete  func(id int) error
+				dup     func() error
+				want    any
+				phone   string
+			}{
+				{
+					name:    "interviewer",
+					get:     func(id int) (any, error) { return store.GetInterviewer(ctx, id) },
+					byPhone: func(phone string) (any, error) { return store.GetInterviewerByPhoneNumber(ctx, phone) },
+					list:    func() (int, error) { l, err := store.ListInterviewers(ctx); return len(l), err },
+					delete:  func(id int) error { return store.DeleteInterviewer(ctx, id) },
+					update: func() error {
+						return store.UpdateInterviewer(ctx, Interviewer{ID: 1, Name: "Ada L", PhoneNumber: "+15550000009", Email: "ada@example.com", TimeZone: "Europe/London", NotificationChannels: []string{ChannelSMS, ChannelEmail}})
+					},
+					dup: func() error {
+						return store.CreateInterviewer(ctx, Interviewer{Name: "Copy", PhoneNumber: "+15550000002", TimeZone: "UTC"})
+					},
+					want:  Interviewer{ID: 1, Name: "Ada L", PhoneNumber: "+15550000009", Email: "ada@example.com", TimeZone: "Europe/London", NotificationChannels: []string{ChannelSMS, ChannelEmail}},
+					phone: "+15550000009",
+				},
+				{
+					name:    "candidate",
+					get:     func(id int) (any, error) { return store.GetCandidate(ctx, id) },
+					byPhone: func(phone string) (any, error) { return store.GetCandidateByPhoneNumber(ctx, phone) },
+					list:    func() (int, error) { l, err := store.ListCandidates(ctx); return len(l), err },
+					delete:  func(id int) error { return store.DeleteCandidate(ctx, id) },
+					update: func() error {
+						return store.UpdateCandidate(ctx, Candidate{ID: 1, Name: "Alan T", PhoneNumber: "+15550000019", Email: "alan@example.com", TimeZone: "Asia/Kolkata", NotificationChannels: []string{ChannelEmail}})
+					},
+					dup: func() error {
+						return store.CreateCandidate(ctx, Candidate{Name: "Copy", PhoneNumber: "+15550000012", TimeZone: "UTC"})
+					},
+					want:  Candidate{ID: 1, Name: "Alan T", PhoneNumber: "+15550000019", Email: "alan@example.com", TimeZone: "Asia/Kolkata", NotificationChannels: []string{ChannelEmail}},
+					phone: "+15550000019",
+				},
+				{
+					name:    "hr",
+					get:     func(id int) (any, error) { return store.GetHR(ctx, id) },
+					byPhone: func(phone string) (any, error) { return store.GetHRByPhoneNumber(ctx, phone) },
+					list:    func() (int, error) { l, err := store.ListHRs(ctx); return len(l), err },
+					delete:  func(id int) error { return store.DeleteHR(ctx, id) },
+					update: func() error {
+						return store.UpdateHR(ctx, HR{ID: 1, Name: "Barbara L", PhoneNumber: "+15550000029", Email: "barbara@example.com", TimeZone: "America/New_York", NotificationChannels: []string{ChannelSMS}})
+					},
+					dup: func() error {
+						return store.CreateHR(ctx, HR{Name: "Copy", PhoneNumber: "+15550000021", TimeZone: "UTC"})
+					},
+					want:  HR{ID: 1, Name: "Barbara L", PhoneNumber: "+15550000029", Email: "barbara@example.com", TimeZone: "America/New_York", NotificationChannels: []string{ChannelSMS}},
+					phone: "+15550000029",
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					// The SQL stores return the driver's error for a taken
+					// phone number, the memory store ErrDuplicate.
+					if err := tt.dup(); err == nil {
+						t.Error("creating someone with a taken phone number succeeded")
+					}
+
+					if err := tt.update(); err != nil {
+						t.Fatal(err)
+					}
+					got, err := tt.get(1)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(got, tt.want) {
+						t.Errorf("get after update: got %+v, want %+v", got, tt.want)
+					}
+
+					got, err = tt.byPhone(tt.phone)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(got, tt.want) {
+						t.Errorf("phone lookup: got %+v, want %+v", got, tt.want)
+					}
+					if _, err := tt.byPhone("+19999999999"); !errors.Is(err, ErrNotFound) {
+						t.Errorf("phone lookup of an unknown number: got %v, want ErrNotFound", err)
+					}
+
+					before, err := tt.list()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := tt.delete(1); err != nil {
+						t.Fatal(err)
+					}
+					if _, err := tt.get(1); !errors.Is(err, ErrNotFound) {
+						t.Errorf("get after delete: got %v, want ErrNotFound", err)
+					}
+					if _, err := tt.byPhone(tt.phone); !errors.Is(err, ErrNotFound) {
+						t.Errorf("phone lookup after delete: got %v, want ErrNotFound", err)
+					}
+					if after, err := tt.list(); err != nil || after != before-1 {
+						t.Errorf("list after delete: got %d (%v), want %d", after, err, before-1)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestStoreInterviews(t *testing.T) {
+	for _, backend := range storeBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.open(t)
+			seedPeople(t, ctx, store)
+
+			interview := testInterview(testTime)
+			id, err := store.CreateInterview(ctx, interview)
+			if err != nil {
+				t.Fatal(err)
+			}
+			interview.ID = id
+			interview.Status = StatusScheduled
+
+			got, err := store.GetInterview(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, interview) {
+				t.Errorf("get after create: got %+v, want %+v", got, interview)
+			}
+
+			interview.ScheduledTime = testTime.Add(2 * time.Hour)
+			interview.EndTime = interview.ScheduledTime.Add(time.Hour)
+			interview.Rescheduled = true
+			interview.Panel = []Panelist{{1, PanelLead}, {2, PanelShadow}}
+			if err := store.UpdateInterview(ctx, id, interview); err != nil {
+				t.Fatal(err)
+			}
+			interview.Sequence = 1
+			got, err = store.GetInterview(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, interview) {
+				t.Errorf("get after update: got %+v, want %+v", got, interview)
+			}
+
+			err = store.SetInterviewStatus(ctx, StatusChange{
+				InterviewID: id,
+				FromStatus:  StatusScheduled,
+				ToStatus:    StatusCancelled,
+				Reason:      "candidate withdrew",
+				CreatedAt:   testTime,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if open, err := store.ListInterviews(ctx, StatusScheduled, StatusConfirmed); err != nil || len(open) != 0 {
+				t.Errorf("open interviews after cancelling: got %d (%v), want none", len(open), err)
+			}
+			if all, err := store.ListInterviews(ctx); err != nil || len(all) != 1 || all[0].Status != StatusCancelled || all[0].Sequence != 2 {
+				t.Errorf("interviews after cancelling: got %+v (%v), want one cancelled at sequence 2", all, err)
+			}
+
+			if err := store.DeleteInterview(ctx, id); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.GetInterview(ctx, id); !errors.Is(err, ErrNotFound) {
+				t.Errorf("get after delete: got %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStoreFindConflicts(t *testing.T) {
+	start, end := testTime, testTime.Add(time.Hour)
+	tests := []struct {
+		name         string
+		existing     Interview
+		cancelled    bool
+		participants []Participant
+		want         bool
+	}{
+		{"ends at window start", testInterview(start.Add(-time.Hour)), false, []Participant{{RoleInterviewer, 1}}, false},
+		{"starts at window end", testInterview(end), false, []Participant{{RoleInterviewer, 1}}, false},
+		{"ends a minute into the window", testInterview(start.Add(-59 * time.Minute)), false, []Participant{{RoleInterviewer, 1}}, true},
+		{"starts a minute before window end", testInterview(end.Add(-time.Minute)), false, []Participant{{RoleInterviewer, 1}}, true},
+		{"same window", testInterview(start), false, []Participant{{RoleInterviewer, 1}}, true},
+		{"candidate", testInterview(start), false, []Participant{{RoleCandidate, 1}}, true},
+		{"hr", testInterview(start), false, []Participant{{RoleHR, 1}}, true},
+		{"panelist", func() Interview {
+			i := testInterview(start)
+			i.Panel = append(i.Panel, Panelist{2, PanelShadow})
+			return i
+		}(), false, []Participant{{RoleInterviewer, 2}}, true},
+		{"someone else", testInterview(start), false, []Participant{{RoleInterviewer, 2}, {RoleCandidate, 2}}, false},
+		{"cancelled", testInterview(start), true, []Participant{{RoleInterviewer, 1}}, false},
+	}
+
+	for _, backend := range storeBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					ctx := context.Background()
+					store := backend.open(t)
+					seedPeople(t, ctx, store)
+
+					id, err := store.CreateInterview(ctx, tt.existing)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if tt.cancelled {
+						err := store.SetInterviewStatus(ctx, StatusChange{InterviewID: id, FromStatus: StatusScheduled, ToStatus: StatusCancelled, CreatedAt: testTime})
+						if err != nil {
+							t.Fatal(err)
+						}
+					}
+
+					conflicts, err := store.FindConflicts(ctx, tt.participants, start, end)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got := len(conflicts) > 0; got != tt.want {
+						t.Errorf("got conflicts %+v, want conflict %v", conflicts, tt.want)
+					}
+					for _, c := range conflicts {
+						if c.InterviewID != id {
+							t.Errorf("conflict with interview %d, want %d", c.InterviewID, id)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	for _, dialect := range sqlDialects() {
+		t.Run(dialect, func(t *testing.T) {
+			ctx := context.Background()
+			db := openTestDB(t, dialect)
+			migrations, err := database.Migrations(dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			status, err := database.Status(ctx, db, dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range status {
+				if s.AppliedAt == nil {
+					t.Errorf("migration %04d_%s is not applied after migrating up", s.Version, s.Name)
+				}
+			}
+
+			for i := len(migrations) - 1; i >= 0; i-- {
+				m, err := database.MigrateDown(ctx, db, dialect)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if m == nil || m.Version != migrations[i].Version {
+					t.Fatalf("migrating down: got %+v, want version %d", m, migrations[i].Version)
+				}
+			}
+			if m, err := database.MigrateDown(ctx, db, dialect); err != nil || m != nil {
+				t.Errorf("migrating down with nothing applied: got %+v (%v), want nil", m, err)
+			}
+			if _, err := db.ExecContext(ctx, "SELECT id FROM interview"); err == nil {
+				t.Error("the interview table still exists after migrating down")
+			}
+
+			applied, err := database.MigrateUp(ctx, db, dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(applied) != len(migrations) {
+				t.Errorf("migrating up again applied %d migrations, want %d", len(applied), len(migrations))
+			}
+			store := newSQLStore(db, dialect)
+			seedPeople(t, ctx, store)
+			if _, err := store.CreateInterview(ctx, testInterview(testTime)); err != nil {
+				t.Errorf("creating an interview after migrating up again: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// suggestionStep is the granularity of suggested start times.
+	suggestionStep = 15 * time.Minute
+	// maxSuggestionRange bounds how far apart from and to may be.
+	maxSuggestionRange = 31 * 24 * time.Hour
+	defaultSuggestions = 10
+	maxSuggestions     = 100
+)
+
+// Suggestion is a slot in which every requested participant is free.
+type Suggestion struct {
+	Rank            int       `json:"rank"`
+	ScheduledTime   time.Time `json:"scheduled_time"`
+	EndTime         time.Time `json:"end_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+}
+
+// freeTime returns the parts of [from, to) in which none of participants
+// is outside their working hours, on time off or within the buffer of
+// another interview, as sorted, non-overlapping intervals.
+func (s *Server) freeTime(ctx context.Context, participants []Participant, from, to time.Time) ([]interval, error) {
+	free := []interval{{from, to}}
+
+	for _, p := range participants {
+		if p.Role != RoleInterviewer && p.Role != RoleHR {
+			continue
+		}
+		hours, err := s.availability.ListWorkingHours(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if len(hours) > 0 {
+			loc, err := participantLocation(ctx, s.people(), p)
+			if err != nil {
+				return nil, err
+			}
+			free = intersectIntervals(free, mergeIntervals(workingWindows(hours, from, to, loc)))
+		}
+
+		offs, err := s.availability.ListTimeOff(ctx, p, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, off := range offs {
+			free = subtractInterval(free, interval{off.StartTime, off.EndTime})
+		}
+	}
+
+	// An interview blocks the buffer around it as well, which is the same
+	// as widening the searched range when looking for conflicts.
+	busy, err := s.interviews.FindConflicts(ctx, participants, from.Add(-s.scheduling.Buffer), to.Add(s.scheduling.Buffer))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range busy {
+		free = subtractInterval(free, interval{c.ScheduledTime.Add(-s.scheduling.Buffer), c.EndTime.Add(s.scheduling.Buffer)})
+	}
+	return free, nil
+}
+
+// intersectIntervals returns the parts covered by both a and b, which must
+// be sorted and non-overlapping.
+func intersectIntervals(a, b []interval) []interval {
+	var out []interval
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].start, a[i].end
+		if b[j].start.After(start) {
+			start = b[j].start
+		}
+		if b[j].end.Before(end) {
+			end = b[j].end
+		}
+		if start.Before(end) {
+			out = append(out, interval{start, end})
+		}
+		if a[i].end.Before(b[j].end) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return out
+}
+
+// subtractInterval removes busy from every interval in free.
+func subtractInterval(free []interval, busy interval) []interval {
+	var out []interval
+	for _, iv := range free {
+		if !busy.start.Before(iv.end) || !busy.end.After(iv.start) {
+			out = append(out, iv)
+			continue
+		}
+		if iv.start.Before(busy.start) {
+			out = append(out, interval{iv.start, busy.start})
+		}
+		if busy.end.Before(iv.end) {
+			out = append(out, interval{busy.end, iv.end})
+		}
+	}
+	return out
+}
+
+// suggestSlots picks up to limit non-overlapping slots of length duration
+// from free, starting on multiples of suggestionStep. Earlier slots rank
+// higher.
+func suggestSlots(free []interval, duration time.Duration, limit int) []Suggestion {
+	var suggestions []Suggestion
+	for _, iv := range free {
+		for start := roundUp(iv.start, suggestionStep); !start.Add(duration).After(iv.end); start = roundUp(start.Add(duration), suggestionStep) {
+			if len(suggestions) == limit {
+				return suggestions
+			}
+			suggestions = append(suggestions, Suggestion{
+				Rank:            len(suggestions) + 1,
+				ScheduledTime:   start,
+				EndTime:         start.Add(duration),
+				DurationMinutes: int(duration / time.Minute),
+			})
+		}
+	}
+	return suggestions
+}
+
+// roundUp returns the first multiple of step at or after t.
+func roundUp(t time.Time, step time.Duration) time.Time {
+	if rounded := t.Truncate(step); rounded.Before(t) {
+		return rounded.Add(step)
+	}
+	return t
+}
+
+// suggestionQuery is the parsed query string of GET /interview/suggestions.
+type suggestionQuery struct {
+	participants []Participant
+	duration     time.Duration
+	from, to     time.Time
+	limit        int
+	loc          *time.Location
+}
+
+func (s *Server) parseSuggestionQuery(r *http.Request) (suggestionQuery, error) {
+	query := r.URL.Query()
+	q := suggestionQuery{
+		duration: s.scheduling.DefaultDuration,
+		limit:    defaultSuggestions,
+		loc:      time.UTC,
+	}
+
+	for _, param := range []struct{ name, role string }{
+		{"interviewer_id", RoleInterviewer},
+		{"hr_id", RoleHR},
+		{"candidate_id", RoleCandidate},
+	} {
+		text := query.Get(param.name)
+		if text == "" {
+			continue
+		}
+		id, err := strconv.Atoi(text)
+		if err != nil {
+			return q, fmt.Errorf("%s must be a number", param.name)
+		}
+		q.participants = append(q.participants, Participant{param.role, id})
+	}
+	if len(q.participants) == 0 {
+		return q, errors.New("at least one of interviewer_id, hr_id and candidate_id is required")
+	}
+
+	if text := query.Get("duration"); text != "" {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			minutes, merr := strconv.Atoi(text)
+			if merr != nil {
+				return q, errors.New("duration must be a number of minutes or a duration such as 45m")
+			}
+			d = time.Duration(minutes) * time.Minute
+		}
+		if d < time.Minute || d > maxInterviewDuration || d%time.Minute != 0 {
+			return q, fmt.Errorf("duration must be a whole number of minutes between 1m and %s", maxInterviewDuration)
+		}
+		q.duration = d
+	}
+
+	if text := query.Get("time_zone"); text != "" {
+		loc, err := time.LoadLocation(text)
+		if err != nil {
+			return q, fmt.Errorf("time_zone %q is not a known IANA time zone", text)
+		}
+		q.loc = loc
+	}
+
+	q.from = time.Now().UTC()
+	if text := query.Get("from"); text != "" {
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return q, errors.New("from must be an RFC 3339 timestamp")
+		}
+		q.from = t.UTC()
+	}
+	q.to = q.from.Add(7 * 24 * time.Hour)
+	if text := query.Get("to"); text != "" {
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return q, errors.New("to must be an RFC 3339 timestamp")
+		}
+		q.to = t.UTC()
+	}
+	if !q.from.Before(q.to) {
+		return q, errors.New("from must be before to")
+	}
+	if q.to.Sub(q.from) > maxSuggestionRange {
+		return q, fmt.Errorf("from and to cannot be more than %d days apart", maxSuggestionRange/(24*time.Hour))
+	}
+
+	if text := query.Get("limit"); text != "" {
+		limit, err := strconv.Atoi(text)
+		if err != nil || limit < 1 || limit > maxSuggestions {
+			return q, fmt.Errorf("limit must be a number between 1 and %d", maxSuggestions)
+		}
+		q.limit = limit
+	}
+	return q, nil
+}
+
+// checkParticipants returns ErrUnknownParticipant when one of participants
+// does not exist.
+func (s *Server) checkParticipants(ctx context.Context, participants []Participant) error {
+	for _, p := range participants {
+		var err error
+		switch p.Role {
+		case RoleInterviewer:
+			_, err = s.interviewers.GetInterviewer(ctx, p.ID)
+		case RoleCandidate:
+			_, err = s.candidates.GetCandidate(ctx, p.ID)
+		case RoleHR:
+			_, err = s.hrs.GetHR(ctx, p.ID)
+		}
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: %s %d does not exist", ErrUnknownParticipant, roleTitle(p.Role), p.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) getSuggestions(w http.ResponseWriter, r *http.Request) {
+	q, err := s.parseSuggestionQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.checkParticipants(r.Context(), q.participants); err != nil {
+		if errors.Is(err, ErrUnknownParticipant) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	free, err := s.freeTime(r.Context(), q.participants, q.from, q.to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	suggestions := suggestSlots(free, q.duration, q.limit)
+	for i := range suggestions {
+		suggestions[i].ScheduledTime = suggestions[i].ScheduledTime.In(q.loc)
+		suggestions[i].EndTime = suggestions[i].EndTime.In(q.loc)
+	}
+	json.NewEncoder(w).Encode(suggestions)
+}
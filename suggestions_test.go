@@ -0,0 +1,158 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntersectIntervals(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []interval
+		want []interval
+	}{
+		{"empty", nil, []interval{span("2030-03-04T09:00:00Z", "2030-03-04T17:00:00Z")}, nil},
+		{
+			"overlapping",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z")},
+			[]interval{span("2030-03-04T10:00:00Z", "2030-03-04T17:00:00Z")},
+			[]interval{span("2030-03-04T10:00:00Z", "2030-03-04T12:00:00Z")},
+		},
+		{
+			"touching",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z")},
+			[]interval{span("2030-03-04T12:00:00Z", "2030-03-04T17:00:00Z")},
+			nil,
+		},
+		{
+			"disjoint",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T10:00:00Z")},
+			[]interval{span("2030-03-04T11:00:00Z", "2030-03-04T12:00:00Z")},
+			nil,
+		},
+		{
+			"one covering several",
+			[]interval{span("2030-03-04T00:00:00Z", "2030-03-05T00:00:00Z")},
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z"), span("2030-03-04T13:00:00Z", "2030-03-04T17:00:00Z")},
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z"), span("2030-03-04T13:00:00Z", "2030-03-04T17:00:00Z")},
+		},
+		{
+			"interleaved",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T11:00:00Z"), span("2030-03-04T12:00:00Z", "2030-03-04T14:00:00Z")},
+			[]interval{span("2030-03-04T10:00:00Z", "2030-03-04T13:00:00Z")},
+			[]interval{span("2030-03-04T10:00:00Z", "2030-03-04T11:00:00Z"), span("2030-03-04T12:00:00Z", "2030-03-04T13:00:00Z")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := intersectIntervals(tt.a, tt.b); !equalIntervals(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := intersectIntervals(tt.b, tt.a); !equalIntervals(got, tt.want) {
+				t.Errorf("swapped: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtractInterval(t *testing.T) {
+	free := []interval{span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z")}
+	tests := []struct {
+		name string
+		busy interval
+		want []interval
+	}{
+		{"before", span("2030-03-04T08:00:00Z", "2030-03-04T09:00:00Z"), free},
+		{"after", span("2030-03-04T12:00:00Z", "2030-03-04T13:00:00Z"), free},
+		{"start", span("2030-03-04T08:00:00Z", "2030-03-04T10:00:00Z"), []interval{span("2030-03-04T10:00:00Z", "2030-03-04T12:00:00Z")}},
+		{"end", span("2030-03-04T11:00:00Z", "2030-03-04T13:00:00Z"), []interval{span("2030-03-04T09:00:00Z", "2030-03-04T11:00:00Z")}},
+		{"middle", span("2030-03-04T10:00:00Z", "2030-03-04T11:00:00Z"), []interval{
+			span("2030-03-04T09:00:00Z", "2030-03-04T10:00:00Z"),
+			span("2030-03-04T11:00:00Z", "2030-03-04T12:00:00Z"),
+		}},
+		{"whole", span("2030-03-04T09:00:00Z", "2030-03-04T12:00:00Z"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subtractInterval(free, tt.busy); !equalIntervals(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestSlots(t *testing.T) {
+	tests := []struct {
+		name     string
+		free     []interval
+		duration time.Duration
+		limit    int
+		want     []string
+	}{
+		{"nothing free", nil, time.Hour, 10, nil},
+		{
+			"up to the end of the range",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T11:00:00Z")}, time.Hour, 10,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-04T10:00:00Z"},
+		},
+		{
+			"rounded to the step",
+			[]interval{span("2030-03-04T09:05:00Z", "2030-03-04T11:00:00Z")}, time.Hour, 10,
+			[]string{"2030-03-04T09:15:00Z"},
+		},
+		{
+			"shorter than the step",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T11:00:00Z")}, 45 * time.Minute, 10,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-04T09:45:00Z"},
+		},
+		{
+			"too short",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T09:59:00Z")}, time.Hour, 10,
+			nil,
+		},
+		{
+			"zero length",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T09:00:00Z")}, time.Hour, 10,
+			nil,
+		},
+		{
+			"without a gap between intervals",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T10:00:00Z"), span("2030-03-04T10:00:00Z", "2030-03-04T11:00:00Z")}, time.Hour, 10,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-04T10:00:00Z"},
+		},
+		{
+			"limited",
+			[]interval{span("2030-03-04T09:00:00Z", "2030-03-04T17:00:00Z")}, time.Hour, 3,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-04T10:00:00Z", "2030-03-04T11:00:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestSlots(tt.free, tt.duration, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want slots at %v", got, tt.want)
+			}
+			for i, s := range got {
+				start, _ := time.Parse(time.RFC3339, tt.want[i])
+				if s.Rank != i+1 || !s.ScheduledTime.Equal(start) || !s.EndTime.Equal(start.Add(tt.duration)) || s.DurationMinutes != int(tt.duration/time.Minute) {
+					t.Errorf("slot %d: got %+v, want rank %d at %s", i, s, i+1, start)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"2030-03-04T09:00:00Z", "2030-03-04T09:00:00Z"},
+		{"2030-03-04T09:00:01Z", "2030-03-04T09:15:00Z"},
+		{"2030-03-04T23:50:00Z", "2030-03-05T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		in, _ := time.Parse(time.RFC3339, tt.in)
+		want, _ := time.Parse(time.RFC3339, tt.want)
+		if got := roundUp(in, suggestionStep); !got.Equal(want) {
+			t.Errorf("roundUp(%s): got %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
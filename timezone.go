@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// peopleStore looks up interview participants of every role.
+type peopleStore interface {
+	InterviewerStore
+	CandidateStore
+	HRStore
+}
+
+// normalizeTimeZone checks that zone is an IANA time zone name such as
+// "Asia/Kolkata". An empty zone means UTC.
+func normalizeTimeZone(zone string) (string, error) {
+	if zone == "" {
+		return "UTC", nil
+	}
+	if _, err := time.LoadLocation(zone); err != nil {
+		return "", fmt.Errorf("time_zone %q is not a known IANA time zone", zone)
+	}
+	return zone, nil
+}
+
+// location returns the location named zone, falling back to UTC for zones
+// stored before they were validated.
+func location(zone string) *time.Location {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// participantLocation returns the time zone of p.
+func participantLocation(ctx context.Context, st peopleStore, p Participant) (*time.Location, error) {
+	var zone string
+	switch p.Role {
+	case RoleInterviewer:
+		interviewer, err := st.GetInterviewer(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		zone = interviewer.TimeZone
+	case RoleCandidate:
+		candidate, err := st.GetCandidate(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		zone = candidate.TimeZone
+	case RoleHR:
+		hr, err := st.GetHR(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		zone = hr.TimeZone
+	}
+	return location(zone), nil
+}
+
+// localTime renders t for a recipient in zone, with the zone abbreviation.
+func localTime(t time.Time, zone string) string {
+	return formatTime(t.In(location(zone)))
+}
+
+// people returns the server's person stores as one peopleStore.
+func (s *Server) people() peopleStore {
+	return struct {
+		InterviewerStore
+		CandidateStore
+		HRStore
+	}{s.interviewers, s.candidates, s.hrs}
+}